@@ -32,14 +32,35 @@ func (c *cmdAdd) command() *cobra.Command {
 type cmdAddDir struct {
 	global *cmdGlobal
 	add    *cmdAdd
+
+	flagHidden string
+}
+
+var hiddenPolicies = map[string]pb.HIDDEN_POLICY{
+	"include":      pb.HIDDEN_POLICY_INCLUDE_HIDDEN,
+	"exclude":      pb.HIDDEN_POLICY_EXCLUDE_HIDDEN,
+	"exclude-dirs": pb.HIDDEN_POLICY_EXCLUDE_HIDDEN_DIRS,
 }
 
 func (c *cmdAddDir) command() *cobra.Command {
 	cmd := new(cobra.Command)
 	cmd.Use = fmt.Sprint("dir <PATH> <PATH> ...")
 	cmd.Short = "Get the directories that are being watched"
+	cmd.Long = common.FormatSection("Description",
+		`Add directories to the watch list.
+
+Hidden files and directories are those whose name starts with a dot, counted
+from the added directory on, so directories like ~/.config can be added as
+well. --hidden decides which of them are synced:
+
+  include        sync all hidden files and directories
+  exclude        skip all hidden files and directories
+  exclude-dirs   skip hidden directories only (default)
+
+Adding a watched directory again with --hidden changes its policy.`)
 
 	cmd.RunE = c.run
+	cmd.Flags().StringVar(&c.flagHidden, "hidden", "", "Hidden path policy: include, exclude or exclude-dirs")
 	return cmd
 }
 
@@ -50,13 +71,27 @@ func (c *cmdAddDir) run(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// The paths of a remote daemon can not be checked here.
 	path := args
-	for idx, val := range args {
-		if !common.PathExist(val) || !common.IsDir(val) {
-			path = append(path[:idx], path[idx+1:]...)
+	if !c.global.remote() {
+		for idx, val := range args {
+			if !common.PathExist(val) || !common.IsDir(val) {
+				path = append(path[:idx], path[idx+1:]...)
+			}
 		}
 	}
 
+	req := &pb.PathList{Values: path}
+	if c.flagHidden != "" {
+		policy, ok := hiddenPolicies[c.flagHidden]
+		if !ok {
+			err := fmt.Errorf("unknown hidden policy %q", c.flagHidden)
+			fmt.Println("Error: ", err)
+			return err
+		}
+		req.HiddenPolicy = &policy
+	}
+
 	err := c.global.initGrpcClient()
 	if err != nil {
 		return err
@@ -65,7 +100,6 @@ func (c *cmdAddDir) run(cmd *cobra.Command, args []string) error {
 
 	client := pb.NewWatchListServiceClient(c.global.conn)
 
-	req := &pb.PathList{Values: path}
 	resp, err := client.AddDirectoriesToWatchList(context.Background(), req)
 	if err != nil {
 		fmt.Println("Error: ", err)
@@ -1,26 +1,59 @@
 package main
 
 import (
+	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/Regis-Caelum/drive-sync/cli/dsync/common"
 	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
 	"github.com/spf13/cobra"
 	"golang.org/x/net/context"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/drive/v3"
-	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
 )
 
 type cmdLogin struct {
 	global *cmdGlobal
+
+	flagDevice         bool
+	flagClientSecret   string
+	flagServiceAccount string
+	flagSubject        string
 }
 
 func (c *cmdLogin) command() *cobra.Command {
 	cmd := new(cobra.Command)
 	cmd.Use = fmt.Sprint("login")
 	cmd.Short = "Login to google and authorize the cli access to google drive"
+	cmd.Long = common.FormatSection("Description",
+		`Login to google and authorize dsync to access google drive.
+
+The authorization page is opened in the browser, which hands the result back
+to dsync on its own. On machines without a browser, --device prints a code to
+enter on another device instead. Google only accepts OAuth clients of the
+type "TVs and Limited Input devices" for it, so --device needs --client-secret
+with such a client, and it only grants access to the files dsync created.
+
+--client-secret logs in with the OAuth client of your own Google Cloud
+project instead of the built-in one. The daemon keeps it for later logins.
+
+--service-account logs in with the JSON key of a service account, which needs
+no browser at all. With --subject, the service account acts as that user of
+its Workspace domain through domain-wide delegation.`)
+
+	cmd.Flags().BoolVar(&c.flagDevice, "device", false, "Log in by entering a code on another device, needs --client-secret")
+	cmd.Flags().StringVar(&c.flagClientSecret, "client-secret", "", "Client secret JSON file of the OAuth client to use")
+	cmd.Flags().StringVar(&c.flagServiceAccount, "service-account", "", "JSON key file of a service account to log in with")
+	cmd.Flags().StringVar(&c.flagSubject, "subject", "", "User the service account acts as")
 
 	cmd.Args = cobra.NoArgs
 	cmd.RunE = c.run
@@ -30,7 +63,33 @@ func (c *cmdLogin) command() *cobra.Command {
 func (c *cmdLogin) run(_ *cobra.Command, args []string) error {
 	ctx := context.Background()
 
-	err := c.global.initGrpcClient()
+	var err error
+	switch {
+	case c.flagSubject != "" && c.flagServiceAccount == "":
+		err = fmt.Errorf("--subject needs --service-account")
+	case c.flagServiceAccount != "" && (c.flagClientSecret != "" || c.flagDevice):
+		err = fmt.Errorf("--service-account can not be combined with --client-secret or --device")
+	case c.flagDevice && c.flagClientSecret == "":
+		err = fmt.Errorf("--device needs --client-secret with an OAuth client of the type \"TVs and Limited Input devices\"")
+	}
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return err
+	}
+
+	var clientSecret []byte
+	if c.flagClientSecret != "" {
+		clientSecret, err = os.ReadFile(c.flagClientSecret)
+		if err == nil {
+			_, err = google.ConfigFromJSON(clientSecret)
+		}
+		if err != nil {
+			fmt.Println("Error: ", err)
+			return err
+		}
+	}
+
+	err = c.global.initGrpcClient()
 	if err != nil {
 		return err
 	}
@@ -38,67 +97,355 @@ func (c *cmdLogin) run(_ *cobra.Command, args []string) error {
 
 	client := pb.NewAuthenticationServiceClient(c.global.conn)
 
-	authToken, err := client.GetToken(ctx, &pb.Empty{})
+	authStatus, err := client.GetAuthStatus(ctx, &pb.Empty{})
 	if err != nil {
 		fmt.Println("Error: ", err)
 		return err
 	}
 
-	if authToken.GetValue() != "" {
+	if authStatus.GetLoggedIn() {
 		fmt.Println("User already logged in.")
 		return nil
 	}
 
 	fmt.Println("User not logged in.")
-	//b, err := os.ReadFile("credentials.json")
-	//if err != nil {
-	//	log.Fatalf("Unable to read client secret file: %v", err)
-	//}
 
-	hashedData := "eyJpbnN0YWxsZWQiOnsiY2xpZW50X2lkIjoiNjU5OTE0NDgzNTUwLXBuNW1icTliN21ibmI2cDFzaWNzM3FwMzU3azRsY3FiLmFwcHMuZ29vZ2xldXNlcmNvbnRlbnQuY29tIiwicHJvamVjdF9pZCI6ImRzeW5jLTQzMzMyMSIsImF1dGhfdXJpIjoiaHR0cHM6Ly9hY2NvdW50cy5nb29nbGUuY29tL28vb2F1dGgyL2F1dGgiLCJ0b2tlbl91cmkiOiJodHRwczovL29hdXRoMi5nb29nbGVhcGlzLmNvbS90b2tlbiIsImF1dGhfcHJvdmlkZXJfeDUwOV9jZXJ0X3VybCI6Imh0dHBzOi8vd3d3Lmdvb2dsZWFwaXMuY29tL29hdXRoMi92MS9jZXJ0cyIsImNsaWVudF9zZWNyZXQiOiJHT0NTUFgtR1UzeTI2b3dvOUF5TE01bFVPTFIzbkFESjB2dCIsInJlZGlyZWN0X3VyaXMiOlsiaHR0cDovL2xvY2FsaG9zdCJdfX0="
+	var in *pb.OAuth2Token
+	if c.flagServiceAccount != "" {
+		key, err := os.ReadFile(c.flagServiceAccount)
+		if err != nil {
+			fmt.Println("Error: ", err)
+			return err
+		}
+		in = &pb.OAuth2Token{Value: string(key), Subject: c.flagSubject}
+	} else {
+		in, err = c.userToken(ctx, client, clientSecret)
+		if err != nil {
+			fmt.Println("Error: ", err)
+			return err
+		}
+	}
 
-	// Unhash (decode) the data
-	b, err := base64.URLEncoding.DecodeString(hashedData)
+	_, err = client.SaveToken(ctx, in)
 	if err != nil {
-		log.Fatalf("Unable to read client secret file: %v", err)
+		fmt.Println("Error: ", err)
+		return err
 	}
-	config, err := google.ConfigFromJSON(b, drive.DriveScope)
+
+	fmt.Println("Successfully logged in.")
+	return nil
+}
+
+type cmdLogout struct {
+	global *cmdGlobal
+
+	flagKeepState       bool
+	flagForgetRemoteIDs bool
+	flagPurgeRemote     bool
+}
+
+func (c *cmdLogout) command() *cobra.Command {
+	cmd := new(cobra.Command)
+	cmd.Use = "logout"
+	cmd.Short = "Log out of google drive"
+	cmd.Long = common.FormatSection("Description",
+		`Revoke the token of the daemon at google and remove it. Nothing is uploaded
+until the next login.
+
+By default the IDs of the uploaded files are kept, so that logging in to the
+same account again continues where it stopped. Logging in to another account
+creates Computers/<host> there and uploads everything again either way.
+
+--forget-remote-ids drops the IDs and queued uploads right away.
+--purge-remote also deletes the folder of this host from drive first.`)
+
+	cmd.Flags().BoolVar(&c.flagKeepState, "keep-state", false, "Keep the IDs of the uploaded files (default)")
+	cmd.Flags().BoolVar(&c.flagForgetRemoteIDs, "forget-remote-ids", false, "Forget which files were uploaded")
+	cmd.Flags().BoolVar(&c.flagPurgeRemote, "purge-remote", false, "Delete the folder of this host from drive")
+
+	cmd.Args = cobra.NoArgs
+	cmd.RunE = c.run
+	return cmd
+}
+
+func (c *cmdLogout) run(_ *cobra.Command, args []string) error {
+	mode := pb.LOGOUT_MODE_KEEP_STATE
+	count := 0
+	for flag, m := range map[*bool]pb.LOGOUT_MODE{
+		&c.flagKeepState:       pb.LOGOUT_MODE_KEEP_STATE,
+		&c.flagForgetRemoteIDs: pb.LOGOUT_MODE_FORGET_REMOTE_IDS,
+		&c.flagPurgeRemote:     pb.LOGOUT_MODE_PURGE_REMOTE,
+	} {
+		if *flag {
+			mode = m
+			count++
+		}
+	}
+	if count > 1 {
+		err := fmt.Errorf("use only one of --keep-state, --forget-remote-ids and --purge-remote")
+		fmt.Println("Error: ", err)
+		return err
+	}
+
+	err := c.global.initGrpcClient()
 	if err != nil {
-		log.Fatalf("Unable to parse client secret file to config: %v", err)
+		return err
 	}
+	defer c.global.closeGrpcClient()
 
-	token := getTokenFromWeb(config)
-	jsonToken, err := json.Marshal(&token)
+	client := pb.NewAuthenticationServiceClient(c.global.conn)
+	_, err = client.Logout(context.Background(), &pb.LogoutRequest{Mode: mode})
 	if err != nil {
 		fmt.Println("Error: ", err)
 		return err
 	}
 
-	_, err = client.SaveToken(ctx, &pb.OAuth2Token{
-		Value: string(jsonToken),
-	})
+	fmt.Println("Successfully logged out.")
+	return nil
+}
+
+type cmdAuth struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAuth) command() *cobra.Command {
+	cmd := new(cobra.Command)
+	cmd.Use = "auth"
+	cmd.Short = "Show the connected account"
+
+	statusCmd := cmdAuthStatus{global: c.global}
+	cmd.AddCommand(statusCmd.command())
+
+	cmd.Args = cobra.NoArgs
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		_ = cmd.Usage()
+		return nil
+	}
+	return cmd
+}
+
+type cmdAuthStatus struct {
+	global *cmdGlobal
+}
+
+func (c *cmdAuthStatus) command() *cobra.Command {
+	cmd := new(cobra.Command)
+	cmd.Use = "status"
+	cmd.Short = "Show the account the daemon is logged in with"
+	cmd.Long = common.FormatSection("Description",
+		`Show the account the daemon is logged in with, the scopes it was granted,
+when the current access token expires and the IDs of the root and host
+folders. The tokens themselves never leave the daemon.`)
+
+	cmd.Args = cobra.NoArgs
+	cmd.RunE = c.run
+	return cmd
+}
+
+func (c *cmdAuthStatus) run(_ *cobra.Command, args []string) error {
+	err := c.global.initGrpcClient()
+	if err != nil {
+		return err
+	}
+	defer c.global.closeGrpcClient()
+
+	client := pb.NewAuthenticationServiceClient(c.global.conn)
+
+	status, err := client.GetAuthStatus(context.Background(), &pb.Empty{})
 	if err != nil {
 		fmt.Println("Error: ", err)
-		return err
+		return fmt.Errorf("failed to connect to dsync daemon: %s", err)
 	}
 
-	fmt.Println("Successfully logged in.")
+	yesNo := map[bool]string{true: "Yes", false: "No"}
+	fields := [][]string{
+		{"Backend", status.GetBackend()},
+		{"Logged in", yesNo[status.GetLoggedIn()]},
+		{"Connected", yesNo[status.GetConnected()]},
+	}
+	if status.GetEmail() != "" {
+		fields = append(fields, []string{"Account", status.GetEmail()})
+	}
+	if status.GetServiceAccount() {
+		fields = append(fields, []string{"Credentials", "Service account"})
+	}
+	if status.GetSubject() != "" {
+		fields = append(fields, []string{"Acting as", status.GetSubject()})
+	}
+	if status.GetClientId() != "" {
+		fields = append(fields, []string{"OAuth client", status.GetClientId()})
+	}
+	if len(status.GetScopes()) > 0 {
+		fields = append(fields, []string{"Scopes", strings.Join(status.GetScopes(), "\n")})
+	}
+	if status.GetExpiry() != 0 {
+		fields = append(fields, []string{"Token expires", time.Unix(status.GetExpiry(), 0).Format("2006-01-02 15:04:05")})
+	}
+	if status.GetRootFolderId() != "" {
+		fields = append(fields, []string{"Root folder", status.GetRootFolderId()})
+	}
+	if status.GetHostFolderId() != "" {
+		fields = append(fields, []string{"Host folder", status.GetHostFolderId()})
+	}
+	if status.GetError() != "" {
+		fields = append(fields, []string{"Error", status.GetError()})
+	}
+
+	for _, field := range fields {
+		for i, line := range strings.Split(field[1], "\n") {
+			if i > 0 {
+				field[0] = ""
+			}
+			fmt.Printf("%-14s %s\n", field[0], line)
+		}
+	}
 	return nil
 }
 
-func getTokenFromWeb(config *oauth2.Config) *oauth2.Token {
-	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
-	fmt.Printf("Go to the following link in your browser then type the "+
-		"authorization code: \n%v\n", authURL)
+// userToken runs the OAuth flow with the given client secret, or with the
+// client the daemon uses when there is none, so that the token is issued to
+// the client that refreshes it later.
+func (c *cmdLogin) userToken(ctx context.Context, client pb.AuthenticationServiceClient, clientSecret []byte) (*pb.OAuth2Token, error) {
+	b := clientSecret
+	if b == nil {
+		stored, err := client.GetOAuthClient(ctx, &pb.Empty{})
+		if err != nil {
+			return nil, err
+		}
+		b = []byte(stored.GetConfig())
+	}
+	config, err := google.ConfigFromJSON(b, drive.DriveScope)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse client secret file to config: %w", err)
+	}
 
-	var authCode string
-	if _, err := fmt.Scan(&authCode); err != nil {
-		log.Fatalf("Unable to read authorization code %v", err)
+	var token *oauth2.Token
+	if c.flagDevice {
+		token, err = deviceLogin(ctx, config)
+	} else {
+		token, err = loopbackLogin(ctx, config)
 	}
+	if err != nil {
+		return nil, err
+	}
+	jsonToken, err := json.Marshal(&token)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.OAuth2Token{Value: string(jsonToken), Client: string(clientSecret)}, nil
+}
+
+// loginTimeout is how long the login flows wait for the user.
+const loginTimeout = 5 * time.Minute
+
+// loopbackLogin runs the authorization code flow with PKCE. Google redirects
+// the browser to a listener on the loopback interface, which receives the
+// code.
+func loopbackLogin(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("unable to listen for the redirect: %w", err)
+	}
+	defer l.Close()
+	config.RedirectURL = "http://" + l.Addr().String() + "/"
+
+	state := make([]byte, 16)
+	_, err = rand.Read(state)
+	if err != nil {
+		return nil, err
+	}
+	expectedState := base64.RawURLEncoding.EncodeToString(state)
+	verifier := oauth2.GenerateVerifier()
+
+	type result struct {
+		code string
+		err  error
+	}
+	done := make(chan result, 1)
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		var res result
+		switch {
+		case query.Get("state") != expectedState:
+			http.Error(w, "Unexpected login request.", http.StatusBadRequest)
+			return
+		case query.Get("error") != "":
+			res.err = fmt.Errorf("login denied: %s", query.Get("error"))
+		case query.Get("code") == "":
+			res.err = fmt.Errorf("no authorization code received")
+		default:
+			res.code = query.Get("code")
+		}
+		if res.err != nil {
+			http.Error(w, "Login failed, see the terminal for details.", http.StatusForbidden)
+		} else {
+			fmt.Fprintln(w, "Logged in to dsync, you can close this window.")
+		}
+		select {
+		case done <- res:
+		default:
+		}
+	})}
+	go srv.Serve(l)
+	defer srv.Close()
+
+	authURL := config.AuthCodeURL(expectedState, oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(verifier))
+	fmt.Printf("Go to the following link in your browser to log in:\n%v\n", authURL)
+	openBrowser(authURL)
 
-	tok, err := config.Exchange(context.TODO(), authCode)
+	var res result
+	select {
+	case res = <-done:
+	case <-time.After(loginTimeout):
+		return nil, fmt.Errorf("no login within %s", loginTimeout)
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+
+	tok, err := config.Exchange(ctx, res.code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve token from web: %w", err)
+	}
+	return tok, nil
+}
+
+// deviceLogin runs the device authorization grant: the user enters a code on
+// any device with a browser while dsync polls for the token. Google limits
+// this flow to the drive.file scope.
+func deviceLogin(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
+	config.Endpoint.DeviceAuthURL = google.Endpoint.DeviceAuthURL
+	config.Scopes = []string{drive.DriveFileScope}
+
+	resp, err := config.DeviceAuth(ctx)
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) && (strings.Contains(string(retrieveErr.Body), "invalid_client") ||
+		strings.Contains(string(retrieveErr.Body), "unauthorized_client")) {
+		return nil, fmt.Errorf("the OAuth client %s does not allow the device login, "+
+			"use one of the type \"TVs and Limited Input devices\"", config.ClientID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to start the device login: %w", err)
+	}
+	fmt.Printf("Go to %s on any device and enter the code %s\n", resp.VerificationURI, resp.UserCode)
+
+	ctx, cancel := context.WithTimeout(ctx, loginTimeout)
+	defer cancel()
+	tok, err := config.DeviceAccessToken(ctx, resp)
 	if err != nil {
-		log.Fatalf("Unable to retrieve token from web %v", err)
+		return nil, fmt.Errorf("unable to retrieve token: %w", err)
+	}
+	return tok, nil
+}
+
+// openBrowser tries to show url in the desktop's browser. Failing is fine,
+// the URL is printed as well.
+func openBrowser(url string) {
+	if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+		return
+	}
+	cmd := exec.Command("xdg-open", url)
+	if cmd.Start() == nil {
+		go cmd.Wait()
 	}
-	return tok
 }
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Regis-Caelum/drive-sync/cli/dsync/common"
+	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+	"path/filepath"
+)
+
+type cmdConflicts struct {
+	global *cmdGlobal
+}
+
+func (c *cmdConflicts) command() *cobra.Command {
+	cmd := new(cobra.Command)
+	cmd.Use = "conflicts"
+	cmd.Short = "List files that changed locally and remotely"
+	cmd.Long = common.FormatSection("Description",
+		`List the files held back because they changed locally and remotely since
+they were last synced. Conflicts are only held back with DSYNC_CONFLICT_POLICY=manual,
+resolve them with dsync conflicts resolve.`)
+
+	resolveCmd := cmdConflictsResolve{global: c.global, conflicts: c}
+	cmd.AddCommand(resolveCmd.command())
+
+	cmd.Args = cobra.NoArgs
+	cmd.RunE = c.run
+	return cmd
+}
+
+func (c *cmdConflicts) run(cmd *cobra.Command, args []string) error {
+	err := c.global.initGrpcClient()
+	if err != nil {
+		return err
+	}
+	defer c.global.closeGrpcClient()
+
+	client := pb.NewWatchListServiceClient(c.global.conn)
+
+	resp, err := client.GetConflicts(context.Background(), &pb.Empty{})
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return fmt.Errorf("failed to connect to dsync daemon: %s", err)
+	}
+	if len(resp.GetFileList()) <= 0 {
+		fmt.Println("No conflicts")
+		return nil
+	}
+
+	headers := []string{
+		"Name",
+		"Path",
+		"Error",
+	}
+	var rows [][]string
+	for _, file := range resp.GetFileList() {
+		rows = append(rows, []string{file.GetName(), file.GetAbsolutePath(), file.GetError()})
+	}
+
+	common.PrintTable(headers, rows)
+	return nil
+}
+
+type cmdConflictsResolve struct {
+	global    *cmdGlobal
+	conflicts *cmdConflicts
+
+	flagKeep string
+}
+
+// conflictKeep maps the values of --keep to the policies.
+var conflictKeep = map[string]pb.CONFLICT_POLICY{
+	"local":  pb.CONFLICT_POLICY_LOCAL_WINS,
+	"remote": pb.CONFLICT_POLICY_REMOTE_WINS,
+	"both":   pb.CONFLICT_POLICY_KEEP_BOTH,
+}
+
+func (c *cmdConflictsResolve) command() *cobra.Command {
+	cmd := new(cobra.Command)
+	cmd.Use = fmt.Sprint("resolve <PATH> <PATH> ...")
+	cmd.Short = "Resolve conflicts by keeping the local, the remote or both versions"
+	cmd.Long = common.FormatSection("Description",
+		`Resolve the conflicts of the given files.
+
+--keep local   uploads the local version over the remote one
+--keep remote  replaces the local version with the remote one
+--keep both    keeps the local version next to the file as
+               <name>.conflict-<host>-<time><ext> and downloads the remote one`)
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagKeep, "keep", "k", "", "Version to keep: local, remote or both")
+	return cmd
+}
+
+func (c *cmdConflictsResolve) run(cmd *cobra.Command, args []string) error {
+	policy, ok := conflictKeep[c.flagKeep]
+	if len(args) < 1 || !ok {
+		fmt.Println("Insufficient arguments")
+		_ = cmd.Usage()
+		return nil
+	}
+
+	err := c.global.initGrpcClient()
+	if err != nil {
+		return err
+	}
+	defer c.global.closeGrpcClient()
+
+	client := pb.NewWatchListServiceClient(c.global.conn)
+
+	var rows [][]string
+	for _, path := range args {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			rows = append(rows, []string{path, "FAILED", err.Error()})
+			continue
+		}
+		_, err = client.ResolveConflict(context.Background(), &pb.ConflictResolution{Path: absPath, Policy: policy})
+		if err != nil {
+			rows = append(rows, []string{absPath, "FAILED", err.Error()})
+			continue
+		}
+		rows = append(rows, []string{absPath, "QUEUED", ""})
+	}
+
+	fmt.Println("Result:")
+	common.PrintTable([]string{"Path", "Status", "Error"}, rows)
+	return nil
+}
@@ -96,11 +96,12 @@ func (c *cmdGetList) run(cmd *cobra.Command, args []string) error {
 		"Track",
 		"Status",
 		"Path",
+		"Error",
 	}
 
 	if c.flagDirOnly {
 		for _, dir := range directoryList {
-			rows = append(rows, []string{dir.GetName(), "Yes", pb.FILE_STATUS_UNTRACKED.String(), pb.FILE_STATUS_UNTRACKED.String(), dir.GetAbsolutePath()})
+			rows = append(rows, []string{dir.GetName(), "Yes", pb.FILE_STATUS_UNTRACKED.String(), pb.FILE_STATUS_UNTRACKED.String(), dir.GetAbsolutePath(), ""})
 		}
 	}
 
@@ -110,7 +111,7 @@ func (c *cmdGetList) run(cmd *cobra.Command, args []string) error {
 			if file.GetIsDir() {
 				isDir = "Yes"
 			}
-			rows = append(rows, []string{file.GetName(), isDir, file.GetFileStatus().String(), file.GetUploadStatus().String(), file.GetAbsolutePath()})
+			rows = append(rows, []string{file.GetName(), isDir, file.GetFileStatus().String(), file.GetUploadStatus().String(), file.GetAbsolutePath(), file.GetError()})
 		}
 	}
 
@@ -132,7 +133,7 @@ func (c *cmdGetList) run(cmd *cobra.Command, args []string) error {
 	//	"Path",
 	//}
 	//for _, dir := range directoryList {
-	//	rows = append(rows, []string{dir.GetName(), dir.GetAbsolutePath()})
+	//	rows = append(rows, []string{dir.GetName(), dir.GetAbsolutePath(), ""})
 	//}
 	//
 	//common.PrintTable(headers, rows)
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Regis-Caelum/drive-sync/cli/dsync/common"
+	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+type cmdHosts struct {
+	global *cmdGlobal
+}
+
+func (c *cmdHosts) command() *cobra.Command {
+	cmd := new(cobra.Command)
+	cmd.Use = "hosts"
+	cmd.Short = "List the machines that uploaded to the backend"
+	cmd.Long = common.FormatSection("Description",
+		`List the host folders below the Computers folder of the backend. Their
+files can be restored with dsync restore --from-host.`)
+
+	cmd.Args = cobra.NoArgs
+	cmd.RunE = c.run
+	return cmd
+}
+
+func (c *cmdHosts) run(cmd *cobra.Command, args []string) error {
+	err := c.global.initGrpcClient()
+	if err != nil {
+		return err
+	}
+	defer c.global.closeGrpcClient()
+
+	client := pb.NewWatchListServiceClient(c.global.conn)
+
+	resp, err := client.ListHosts(context.Background(), &pb.Empty{})
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return fmt.Errorf("failed to connect to dsync daemon: %s", err)
+	}
+	if len(resp.GetHosts()) <= 0 {
+		fmt.Println("No hosts have uploaded yet")
+		return nil
+	}
+
+	var rows [][]string
+	for _, host := range resp.GetHosts() {
+		current := ""
+		if host.GetCurrent() {
+			current = "Yes"
+		}
+		rows = append(rows, []string{host.GetName(), current})
+	}
+
+	common.PrintTable([]string{"Host", "This machine"}, rows)
+	return nil
+}
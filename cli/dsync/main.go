@@ -1,21 +1,35 @@
 package main
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"github.com/Regis-Caelum/drive-sync/cli/dsync/common"
+	"github.com/Regis-Caelum/drive-sync/config"
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"os"
 )
 
 type cmdGlobal struct {
 	conn *grpc.ClientConn
+
+	flagContext string
 }
 
 func (g *cmdGlobal) initGrpcClient() error {
 	var err error
 	if g.conn == nil {
-		g.conn, err = grpc.NewClient(":58295", grpc.WithTransportCredentials(insecure.NewCredentials()))
+		var target string
+		var creds credentials.TransportCredentials
+		target, creds, err = g.dialOptions()
+		if err != nil {
+			fmt.Println("Error: ", err)
+			return err
+		}
+		g.conn, err = grpc.NewClient(target, grpc.WithTransportCredentials(creds))
 		if err != nil {
 			fmt.Printf("Error: %s", err)
 			return fmt.Errorf("failed to connect to dsync daemon: %s", err)
@@ -25,6 +39,58 @@ func (g *cmdGlobal) initGrpcClient() error {
 	return nil
 }
 
+// contextName returns the name of the remote daemon to connect to, or "" for
+// the local one.
+func (g *cmdGlobal) contextName() string {
+	if g.flagContext != "" {
+		return g.flagContext
+	}
+	if name := os.Getenv("DSYNC_CONTEXT"); name != "" {
+		return name
+	}
+	return config.Client().Context
+}
+
+// remote reports whether the commands manage a daemon on another machine.
+func (g *cmdGlobal) remote() bool {
+	return g.contextName() != ""
+}
+
+// dialOptions returns the address and credentials of the daemon: the local
+// socket, or the TCP address of the selected context with TLS and the client
+// certificate of the context.
+func (g *cmdGlobal) dialOptions() (string, credentials.TransportCredentials, error) {
+	cfg := config.Client()
+	name := g.contextName()
+	if name == "" {
+		return "unix:" + cfg.Listen, insecure.NewCredentials(), nil
+	}
+
+	c, ok := cfg.Contexts[name]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown context %q", name)
+	}
+	cert, err := tls.LoadX509KeyPair(c.Cert, c.Key)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to load the client certificate: %w", err)
+	}
+	ca, err := os.ReadFile(c.CA)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to read the ca: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return "", nil, fmt.Errorf("no certificates found in %s", c.CA)
+	}
+
+	return c.Address, credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		ServerName:   c.ServerName,
+		MinVersion:   tls.VersionTLS12,
+	}), nil
+}
+
 func (g *cmdGlobal) closeGrpcClient() {
 	var err error
 	err = g.conn.Close()
@@ -49,6 +115,8 @@ For help with any of those, simply call them with --help.`)
 
 	globalCmd := &cmdGlobal{}
 	app.PersistentFlags().BoolP("help", "h", false, "Print help")
+	app.PersistentFlags().StringVar(&globalCmd.flagContext, "context", "",
+		"Manage the remote daemon of this context from the configuration")
 
 	app.InitDefaultHelpCmd()
 
@@ -58,11 +126,29 @@ For help with any of those, simply call them with --help.`)
 	addCmd := &cmdAdd{global: globalCmd}
 	app.AddCommand(addCmd.command())
 
-	authCmd := &cmdLogin{global: globalCmd}
+	loginCmd := &cmdLogin{global: globalCmd}
+	app.AddCommand(loginCmd.command())
+
+	logoutCmd := &cmdLogout{global: globalCmd}
+	app.AddCommand(logoutCmd.command())
+
+	authCmd := &cmdAuth{global: globalCmd}
 	app.AddCommand(authCmd.command())
 
-	//authCmd := &cmdAuth{global: globalCmd}
-	//app.AddCommand(authCmd.command())
+	conflictsCmd := &cmdConflicts{global: globalCmd}
+	app.AddCommand(conflictsCmd.command())
+
+	pullCmd := &cmdPull{global: globalCmd}
+	app.AddCommand(pullCmd.command())
+
+	hostsCmd := &cmdHosts{global: globalCmd}
+	app.AddCommand(hostsCmd.command())
+
+	restoreCmd := &cmdRestore{global: globalCmd}
+	app.AddCommand(restoreCmd.command())
+
+	revisionsCmd := &cmdRevisions{global: globalCmd}
+	app.AddCommand(revisionsCmd.command())
 
 	//app.SetArgs([]string{"add", "dir"})
 	//app.SetArgs([]string{"get", "list", "-df"})
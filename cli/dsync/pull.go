@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Regis-Caelum/drive-sync/cli/dsync/common"
+	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/status"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+type cmdPull struct {
+	global *cmdGlobal
+
+	flagTo     string
+	flagDryRun bool
+}
+
+func (c *cmdPull) command() *cobra.Command {
+	cmd := new(cobra.Command)
+	cmd.Use = fmt.Sprint("pull <PATH>")
+	cmd.Short = "Download a watched directory or file from the backend"
+	cmd.Long = common.FormatSection("Description",
+		`Download a directory or file from the backend.
+
+PATH is either the local path the files were uploaded from, or their remote
+path like Computers/<host>/home/me/docs, which may belong to another host.
+The files are restored to the path they were uploaded from, or into the
+directory given with --to.
+
+Files that are up to date are skipped. Interrupted downloads are resumed by
+pulling again.`)
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagTo, "to", "t", "", "Directory to restore into instead of the original path")
+	cmd.Flags().BoolVarP(&c.flagDryRun, "dry-run", "n", false, "Only list the files that would be downloaded")
+	return cmd
+}
+
+func (c *cmdPull) run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		fmt.Println("Insufficient arguments")
+		_ = cmd.Usage()
+		return nil
+	}
+
+	req := &pb.PullRequest{Path: args[0], DryRun: c.flagDryRun}
+	if !strings.HasPrefix(filepath.ToSlash(args[0]), "Computers/") {
+		path, err := filepath.Abs(args[0])
+		if err != nil {
+			return err
+		}
+		req.Path = path
+	}
+	if c.flagTo != "" {
+		to, err := filepath.Abs(c.flagTo)
+		if err != nil {
+			return err
+		}
+		req.To = to
+	}
+	return runPull(c.global, req)
+}
+
+// runPull streams a pull from the daemon and prints its progress. It is
+// shared by pull and restore.
+func runPull(global *cmdGlobal, req *pb.PullRequest) error {
+	err := global.initGrpcClient()
+	if err != nil {
+		return err
+	}
+	defer global.closeGrpcClient()
+
+	client := pb.NewWatchListServiceClient(global.conn)
+
+	stream, err := client.Pull(context.Background(), req)
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return fmt.Errorf("failed to connect to dsync daemon: %s", err)
+	}
+
+	counts := make(map[pb.PULL_STATUS]int)
+	for {
+		progress, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			fmt.Println("Error: ", status.Convert(err).Message())
+			return fmt.Errorf("pull failed: %s", err)
+		}
+
+		switch progress.GetStatus() {
+		case pb.PULL_STATUS_DOWNLOADING:
+			fmt.Printf("\r%s  %s / %s", progress.GetPath(), formatBytes(progress.GetOffset()),
+				formatBytes(progress.GetSize()))
+			continue
+		case pb.PULL_STATUS_DOWNLOADED:
+			fmt.Printf("\r%s  %s  done\n", progress.GetPath(), formatBytes(progress.GetSize()))
+		case pb.PULL_STATUS_UP_TO_DATE:
+			fmt.Printf("%s  up to date\n", progress.GetPath())
+		case pb.PULL_STATUS_WOULD_DOWNLOAD:
+			fmt.Printf("%s  %s\n", progress.GetPath(), formatBytes(progress.GetSize()))
+		default:
+			fmt.Printf("\r%s  %s: %s\n", progress.GetPath(), progress.GetStatus(), progress.GetError())
+		}
+		counts[progress.GetStatus()]++
+	}
+
+	if req.GetDryRun() {
+		fmt.Printf("%d files would be downloaded, %d are up to date, %d would be skipped\n",
+			counts[pb.PULL_STATUS_WOULD_DOWNLOAD], counts[pb.PULL_STATUS_UP_TO_DATE],
+			counts[pb.PULL_STATUS_DOWNLOAD_SKIPPED])
+		return nil
+	}
+	fmt.Printf("%d files downloaded, %d up to date, %d skipped, %d failed\n",
+		counts[pb.PULL_STATUS_DOWNLOADED], counts[pb.PULL_STATUS_UP_TO_DATE],
+		counts[pb.PULL_STATUS_DOWNLOAD_SKIPPED], counts[pb.PULL_STATUS_DOWNLOAD_FAILED])
+	if counts[pb.PULL_STATUS_DOWNLOAD_FAILED] > 0 {
+		return fmt.Errorf("%d files failed to download", counts[pb.PULL_STATUS_DOWNLOAD_FAILED])
+	}
+	return nil
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
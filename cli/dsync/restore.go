@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Regis-Caelum/drive-sync/cli/dsync/common"
+	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
+	"github.com/spf13/cobra"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type cmdRestore struct {
+	global *cmdGlobal
+
+	flagHost   string
+	flagAt     string
+	flagTo     string
+	flagDryRun bool
+}
+
+// restoreTimeLayouts are the formats accepted by restore --at, in local time.
+var restoreTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	time.RFC3339,
+}
+
+func (c *cmdRestore) command() *cobra.Command {
+	cmd := new(cobra.Command)
+	cmd.Use = fmt.Sprint("restore [--from-host <HOST>] [--at <TIME>] <PATH>")
+	cmd.Short = "Download files another machine uploaded, or earlier versions of files"
+	cmd.Long = common.FormatSection("Description",
+		`Download a directory or file from the host folder of another machine, for
+instance to set up a replacement laptop, or as it was at an earlier time.
+
+With --from-host, PATH is the absolute path the files had on that machine.
+They are restored to the same path on this machine, or into the directory
+given with --to. Restored files inside watched directories are uploaded again
+as files of this machine.
+
+With --at, every file is downloaded in the revision that was current at that
+time, like --at "2026-10-01 12:00". Earlier contents restored to their
+original path are uploaded again as the current revision. Only Google Drive
+keeps revisions.`)
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagHost, "from-host", "H", "", "Host to restore from, see dsync hosts")
+	cmd.Flags().StringVarP(&c.flagAt, "at", "a", "", "Restore the files as they were at this local time")
+	cmd.Flags().StringVarP(&c.flagTo, "to", "t", "", "Directory to restore into instead of the original path")
+	cmd.Flags().BoolVarP(&c.flagDryRun, "dry-run", "n", false, "Only list the files that would be downloaded")
+	return cmd
+}
+
+func (c *cmdRestore) run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 || (c.flagHost == "" && c.flagAt == "") {
+		fmt.Println("Insufficient arguments")
+		_ = cmd.Usage()
+		return nil
+	}
+
+	req := &pb.PullRequest{Path: args[0], Host: c.flagHost, DryRun: c.flagDryRun}
+	if !filepath.IsAbs(args[0]) && !strings.HasPrefix(filepath.ToSlash(args[0]), "Computers/") {
+		if c.flagHost != "" {
+			fmt.Println("Error: ", "PATH has to be the absolute path the files had on", c.flagHost)
+			return fmt.Errorf("relative path %s", args[0])
+		}
+		path, err := filepath.Abs(args[0])
+		if err != nil {
+			return err
+		}
+		req.Path = path
+	}
+	if c.flagAt != "" {
+		at, err := parseRestoreTime(c.flagAt)
+		if err != nil {
+			fmt.Println("Error: ", err)
+			return err
+		}
+		req.At = at.Unix()
+	}
+	if c.flagTo != "" {
+		to, err := filepath.Abs(c.flagTo)
+		if err != nil {
+			return err
+		}
+		req.To = to
+	}
+	return runPull(c.global, req)
+}
+
+func parseRestoreTime(value string) (time.Time, error) {
+	for _, layout := range restoreTimeLayouts {
+		t, err := time.ParseInLocation(layout, value, time.Local)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, use a format like \"2006-01-02 15:04\"", value)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Regis-Caelum/drive-sync/cli/dsync/common"
+	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+	"path/filepath"
+	"time"
+)
+
+type cmdRevisions struct {
+	global *cmdGlobal
+}
+
+func (c *cmdRevisions) command() *cobra.Command {
+	cmd := new(cobra.Command)
+	cmd.Use = fmt.Sprint("revisions <PATH>")
+	cmd.Short = "List the revisions the backend keeps of a file"
+	cmd.Long = common.FormatSection("Description",
+		`List the earlier contents the backend keeps of an uploaded file. They can be
+restored with dsync restore --at. Only Google Drive keeps revisions.`)
+
+	cmd.RunE = c.run
+	return cmd
+}
+
+func (c *cmdRevisions) run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		fmt.Println("Insufficient arguments")
+		_ = cmd.Usage()
+		return nil
+	}
+	path, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+
+	err = c.global.initGrpcClient()
+	if err != nil {
+		return err
+	}
+	defer c.global.closeGrpcClient()
+
+	client := pb.NewWatchListServiceClient(c.global.conn)
+
+	resp, err := client.GetRevisions(context.Background(), &pb.RevisionRequest{Path: path})
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return fmt.Errorf("failed to connect to dsync daemon: %s", err)
+	}
+
+	headers := []string{
+		"Revision",
+		"Modified",
+		"Size",
+		"MD5",
+		"Kept forever",
+	}
+	var rows [][]string
+	for _, r := range resp.GetRevisions() {
+		kept := ""
+		if r.GetKeepForever() {
+			kept = "Yes"
+		}
+		rows = append(rows, []string{r.GetId(), time.Unix(r.GetModifiedTime(), 0).Format("2006-01-02 15:04:05"),
+			formatBytes(r.GetSize()), r.GetMd5(), kept})
+	}
+
+	common.PrintTable(headers, rows)
+	return nil
+}
@@ -0,0 +1,348 @@
+// Package config reads the settings of the dsync daemon and client from
+// /etc/dsync/config.toml and the per-user override in
+// ~/.config/dsync/config.toml. The DSYNC_* environment variables take
+// precedence over both files. The client only reads the listen address and
+// the contexts, so the files may hold backend secrets only root can read.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// SystemFile is read first, unless DSYNC_CONFIG names a different file.
+	SystemFile = "/etc/dsync/config.toml"
+
+	DefaultListen          = "/run/dsync/dsync.sock"
+	DefaultDebugListen     = "./daemon/dsync.sock"
+	DefaultDatabase        = "/var/lib/dsync/database.sqlite"
+	DefaultDebugDatabase   = "./daemon/database/database.sqlite"
+	DefaultUploadWorkers   = 4
+	DefaultUploadChunkSize = 8
+	DefaultIgnoreFile      = "/etc/dsync/ignore"
+	DefaultBackend         = "drive"
+)
+
+// Config holds all settings. Sizes are in MiB, bandwidth limits in KiB per
+// second.
+type Config struct {
+	// Listen is the path of the Unix socket of the daemon. ListenTCP is an
+	// address the daemon serves on in addition, none by default. It uses TLS
+	// when TLS is set.
+	Listen          string   `toml:"listen"`
+	ListenTCP       string   `toml:"listen_tcp"`
+	TLS             TLS      `toml:"tls"`
+	Database        string   `toml:"database"`
+	UploadWorkers   int      `toml:"upload_workers"`
+	UploadChunkSize int64    `toml:"upload_chunk_size"`
+	ConflictPolicy  string   `toml:"conflict_policy"`
+	KeepRevisions   []string `toml:"keep_revisions"`
+
+	// TokenKey selects where the key sealing the stored OAuth token is kept:
+	// "keyring", "file" or "auto", which prefers the keyring when a session
+	// bus is present. TokenKeyFile defaults to token.key next to the
+	// database.
+	TokenKey     string `toml:"token_key"`
+	TokenKeyFile string `toml:"token_key_file"`
+
+	// IgnoreFile holds global ignore rules, Ignore lists more rules inline.
+	IgnoreFile string   `toml:"ignore_file"`
+	Ignore     []string `toml:"ignore"`
+
+	Access    Access    `toml:"access"`
+	Bandwidth Bandwidth `toml:"bandwidth"`
+	Backend   Backend   `toml:"backend"`
+
+	// Context names the entry of Contexts the client connects to instead of
+	// the local socket, unless the --context flag or DSYNC_CONTEXT names
+	// another one.
+	Context  string             `toml:"context"`
+	Contexts map[string]Context `toml:"contexts"`
+}
+
+// TLS holds the certificate of the daemon and the CA client certificates
+// have to be signed by.
+type TLS struct {
+	Cert     string `toml:"cert"`
+	Key      string `toml:"key"`
+	ClientCA string `toml:"client_ca"`
+}
+
+// Context describes a remote daemon the client can manage: its TCP address,
+// the CA its certificate is signed by and the client certificate.
+type Context struct {
+	Address    string `toml:"address"`
+	CA         string `toml:"ca"`
+	Cert       string `toml:"cert"`
+	Key        string `toml:"key"`
+	ServerName string `toml:"server_name"`
+}
+
+// Access controls who may talk to the daemon. Without a SocketGroup every
+// local user may connect to the socket.
+type Access struct {
+	// Admins are the users that may do everything, like root and the user
+	// running the daemon. Everyone else is confined to their home directory.
+	Admins      []string `toml:"admins"`
+	SocketGroup string   `toml:"socket_group"`
+}
+
+// Bandwidth limits the transfer rate of all uploads and of all downloads
+// together. 0 means unlimited.
+type Bandwidth struct {
+	Upload   int64 `toml:"upload"`
+	Download int64 `toml:"download"`
+}
+
+// Backend selects the storage backend by Name and holds the settings of each
+// of them.
+type Backend struct {
+	Name   string `toml:"name"`
+	Local  Local  `toml:"local"`
+	S3     S3     `toml:"s3"`
+	WebDAV WebDAV `toml:"webdav"`
+	SFTP   SFTP   `toml:"sftp"`
+}
+
+type Local struct {
+	Root string `toml:"root"`
+}
+
+type S3 struct {
+	Endpoint  string `toml:"endpoint"`
+	Bucket    string `toml:"bucket"`
+	AccessKey string `toml:"access_key"`
+	SecretKey string `toml:"secret_key"`
+	Region    string `toml:"region"`
+	Insecure  bool   `toml:"insecure"`
+}
+
+type WebDAV struct {
+	URL      string `toml:"url"`
+	User     string `toml:"user"`
+	Password string `toml:"password"`
+}
+
+type SFTP struct {
+	Host       string `toml:"host"`
+	User       string `toml:"user"`
+	Root       string `toml:"root"`
+	Key        string `toml:"key"`
+	KnownHosts string `toml:"known_hosts"`
+}
+
+var (
+	current  atomic.Pointer[Config]
+	loadOnce sync.Once
+
+	client     *Config
+	clientOnce sync.Once
+)
+
+// Current returns the settings in effect. They are loaded on first use, an
+// invalid configuration is fatal then.
+func Current() *Config {
+	loadOnce.Do(func() {
+		if current.Load() != nil {
+			return
+		}
+		cfg, err := Load()
+		if err != nil {
+			log.Fatal("invalid configuration: ", err)
+		}
+		current.Store(cfg)
+	})
+	return current.Load()
+}
+
+// Client returns the settings of the client, loaded on first use by
+// LoadClient. Invalid client settings are fatal.
+func Client() *Config {
+	clientOnce.Do(func() {
+		cfg, err := LoadClient()
+		if err != nil {
+			log.Fatal("invalid configuration: ", err)
+		}
+		client = cfg
+	})
+	return client
+}
+
+// Set replaces the settings in effect.
+func Set(cfg *Config) {
+	current.Store(cfg)
+}
+
+// Files returns the configuration files in the order they are applied.
+func Files() []string {
+	files := []string{SystemFile}
+	if path := os.Getenv("DSYNC_CONFIG"); path != "" {
+		files[0] = path
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		files = append(files, filepath.Join(dir, "dsync", "config.toml"))
+	}
+	return files
+}
+
+// Load reads the settings from the defaults, the configuration files and the
+// environment, each overriding the one before. Missing files are skipped.
+func Load() (*Config, error) {
+	cfg := &Config{
+		Listen:          DefaultListen,
+		Database:        DefaultDatabase,
+		UploadWorkers:   DefaultUploadWorkers,
+		UploadChunkSize: DefaultUploadChunkSize,
+		IgnoreFile:      DefaultIgnoreFile,
+		Backend:         Backend{Name: DefaultBackend},
+	}
+	if os.Getenv("DEBUG_MODE") == "true" {
+		cfg.Listen = DefaultDebugListen
+		cfg.Database = DefaultDebugDatabase
+	}
+
+	for _, path := range Files() {
+		err := loadFile(cfg, path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	applyEnv(cfg)
+	if cfg.TokenKeyFile == "" {
+		cfg.TokenKeyFile = filepath.Join(filepath.Dir(cfg.Database), "token.key")
+	}
+	return cfg, cfg.validate()
+}
+
+// LoadClient reads the settings the client needs: the listen address and the
+// contexts. Other settings are not looked at, and files the user may not read
+// are skipped, as they usually hold the secrets of the daemon.
+func LoadClient() (*Config, error) {
+	cfg := &Config{Listen: DefaultListen}
+	if os.Getenv("DEBUG_MODE") == "true" {
+		cfg.Listen = DefaultDebugListen
+	}
+
+	for _, path := range Files() {
+		settings := struct {
+			Listen   *string             `toml:"listen"`
+			Context  *string             `toml:"context"`
+			Contexts *map[string]Context `toml:"contexts"`
+		}{&cfg.Listen, &cfg.Context, &cfg.Contexts}
+		_, err := toml.DecodeFile(path, &settings)
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return cfg, cfg.validateClient()
+}
+
+func loadFile(cfg *Config, path string) error {
+	meta, err := toml.DecodeFile(path, cfg)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) != 0 {
+		return fmt.Errorf("%s: unknown setting %s", path, undecoded[0])
+	}
+	return nil
+}
+
+func applyEnv(cfg *Config) {
+	values := map[string]*string{
+		"DSYNC_CONFLICT_POLICY":  &cfg.ConflictPolicy,
+		"DSYNC_IGNORE_FILE":      &cfg.IgnoreFile,
+		"DSYNC_BACKEND":          &cfg.Backend.Name,
+		"DSYNC_LOCAL_ROOT":       &cfg.Backend.Local.Root,
+		"DSYNC_S3_ENDPOINT":      &cfg.Backend.S3.Endpoint,
+		"DSYNC_S3_BUCKET":        &cfg.Backend.S3.Bucket,
+		"DSYNC_S3_ACCESS_KEY":    &cfg.Backend.S3.AccessKey,
+		"DSYNC_S3_SECRET_KEY":    &cfg.Backend.S3.SecretKey,
+		"DSYNC_S3_REGION":        &cfg.Backend.S3.Region,
+		"DSYNC_WEBDAV_URL":       &cfg.Backend.WebDAV.URL,
+		"DSYNC_WEBDAV_USER":      &cfg.Backend.WebDAV.User,
+		"DSYNC_WEBDAV_PASSWORD":  &cfg.Backend.WebDAV.Password,
+		"DSYNC_SFTP_HOST":        &cfg.Backend.SFTP.Host,
+		"DSYNC_SFTP_USER":        &cfg.Backend.SFTP.User,
+		"DSYNC_SFTP_ROOT":        &cfg.Backend.SFTP.Root,
+		"DSYNC_SFTP_KEY":         &cfg.Backend.SFTP.Key,
+		"DSYNC_SFTP_KNOWN_HOSTS": &cfg.Backend.SFTP.KnownHosts,
+	}
+	for name, field := range values {
+		if value := os.Getenv(name); value != "" {
+			*field = value
+		}
+	}
+
+	if value := os.Getenv("DSYNC_S3_INSECURE"); value != "" {
+		cfg.Backend.S3.Insecure = value == "true"
+	}
+	if value := os.Getenv("DSYNC_KEEP_REVISIONS"); value != "" {
+		cfg.KeepRevisions = filepath.SplitList(value)
+	}
+	// Invalid numbers were ignored before the configuration file existed.
+	if workers, err := strconv.Atoi(os.Getenv("DSYNC_UPLOAD_WORKERS")); err == nil && workers >= 1 {
+		cfg.UploadWorkers = workers
+	}
+	if size, err := strconv.ParseInt(os.Getenv("DSYNC_UPLOAD_CHUNK_SIZE"), 10, 64); err == nil && size >= 1 {
+		cfg.UploadChunkSize = size
+	}
+}
+
+func (cfg *Config) validateClient() error {
+	if cfg.Listen == "" {
+		return errors.New("listen must not be empty")
+	}
+	for name, c := range cfg.Contexts {
+		if c.Address == "" || c.CA == "" || c.Cert == "" || c.Key == "" {
+			return fmt.Errorf("context %s needs address, ca, cert and key", name)
+		}
+	}
+	return nil
+}
+
+func (cfg *Config) validate() error {
+	err := cfg.validateClient()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case cfg.Database == "":
+		return errors.New("database must not be empty")
+	case cfg.UploadWorkers < 1:
+		return fmt.Errorf("upload_workers must be at least 1, not %d", cfg.UploadWorkers)
+	case cfg.UploadChunkSize < 1:
+		return fmt.Errorf("upload_chunk_size must be at least 1, not %d", cfg.UploadChunkSize)
+	case cfg.Bandwidth.Upload < 0 || cfg.Bandwidth.Download < 0:
+		return errors.New("bandwidth limits must not be negative")
+	case cfg.TokenKey != "" && cfg.TokenKey != "auto" && cfg.TokenKey != "keyring" && cfg.TokenKey != "file":
+		return fmt.Errorf("unknown token_key %q, use auto, keyring or file", cfg.TokenKey)
+	case cfg.TLS != TLS{} && (cfg.TLS.Cert == "" || cfg.TLS.Key == "" || cfg.TLS.ClientCA == ""):
+		return errors.New("tls needs cert, key and client_ca")
+	}
+
+	switch cfg.Backend.Name {
+	case "drive", "local", "s3", "webdav", "sftp":
+	default:
+		return fmt.Errorf("unknown backend %q, use one of drive, local, s3, webdav or sftp",
+			cfg.Backend.Name)
+	}
+	return nil
+}
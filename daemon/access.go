@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/Regis-Caelum/drive-sync/config"
+	"github.com/Regis-Caelum/drive-sync/daemon/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// peerCredentials are the transport credentials of the gRPC server. They
+// record the UID of callers on the Unix socket. TCP connections use TLS with
+// client certificates when it is configured, and are not encrypted otherwise.
+type peerCredentials struct{}
+
+// peerInfo identifies the caller of a connection. Callers on the Unix socket
+// are local and have a UID, callers with a client certificate are certified
+// and named by its common name.
+type peerInfo struct {
+	credentials.CommonAuthInfo
+	uid       uint32
+	local     bool
+	certified bool
+	name      string
+}
+
+func (peerInfo) AuthType() string {
+	return "peercred"
+}
+
+func (peerCredentials) ClientHandshake(_ context.Context, _ string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return conn, peerInfo{CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.NoSecurity}}, nil
+}
+
+func (peerCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	info := peerInfo{CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.NoSecurity}}
+	if unixConn, ok := conn.(*net.UnixConn); ok {
+		uid, err := peerUID(unixConn)
+		if err != nil {
+			return nil, nil, err
+		}
+		info.uid = uid
+		info.local = true
+		return conn, info, nil
+	}
+
+	creds, err := serverTLS(config.Current())
+	if err != nil || creds == nil {
+		return conn, info, err
+	}
+	conn, auth, err := creds.ServerHandshake(conn)
+	if err != nil {
+		return nil, nil, err
+	}
+	tlsInfo := auth.(credentials.TLSInfo)
+	info.CommonAuthInfo = tlsInfo.CommonAuthInfo
+	info.certified = true
+	info.name = tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+	return conn, info, nil
+}
+
+func (peerCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: "peercred"}
+}
+
+// tlsCache holds the TLS credentials of the configuration they were loaded
+// for, so that certificates are read again after a reload only.
+var tlsCache struct {
+	sync.Mutex
+	cfg   *config.Config
+	creds credentials.TransportCredentials
+}
+
+// serverTLS returns the TLS credentials of the TCP listener, or nil when TLS
+// is not configured. Clients have to present a certificate signed by the
+// configured CA.
+func serverTLS(cfg *config.Config) (credentials.TransportCredentials, error) {
+	if cfg.TLS.Cert == "" {
+		return nil, nil
+	}
+
+	tlsCache.Lock()
+	defer tlsCache.Unlock()
+	if tlsCache.cfg == cfg {
+		return tlsCache.creds, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.TLS.Cert, cfg.TLS.Key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the tls certificate: %w", err)
+	}
+	ca, err := os.ReadFile(cfg.TLS.ClientCA)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the client ca: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificates found in %s", cfg.TLS.ClientCA)
+	}
+
+	tlsCache.cfg = cfg
+	tlsCache.creds = credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	})
+	return tlsCache.creds, nil
+}
+
+func (c peerCredentials) Clone() credentials.TransportCredentials {
+	return c
+}
+
+func (peerCredentials) OverrideServerName(string) error {
+	return nil
+}
+
+// caller is the user on the other end of a call. Certified callers act as the
+// local user named like their certificate.
+type caller struct {
+	uid       uint32
+	local     bool
+	certified bool
+	name      string
+	homes     []string
+}
+
+// callerFrom returns the user that made the call.
+func callerFrom(ctx context.Context) *caller {
+	c := new(caller)
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return c
+	}
+	info, ok := p.AuthInfo.(peerInfo)
+	if !ok {
+		return c
+	}
+
+	var u *user.User
+	var err error
+	switch {
+	case info.local:
+		c.uid = info.uid
+		c.local = true
+		u, err = user.LookupId(strconv.FormatUint(uint64(info.uid), 10))
+	case info.certified:
+		c.certified = true
+		c.name = info.name
+		u, err = user.Lookup(info.name)
+	default:
+		return c
+	}
+	if err == nil {
+		c.name = u.Username
+		// Paths are compared with the home directory as it is written and
+		// with the directory it links to.
+		if u.HomeDir != "" && u.HomeDir != "/" {
+			c.homes = []string{filepath.Clean(u.HomeDir)}
+			if resolved := resolvePath(u.HomeDir); resolved != c.homes[0] {
+				c.homes = append(c.homes, resolved)
+			}
+		}
+	}
+	return c
+}
+
+func (c *caller) String() string {
+	switch {
+	case !c.local && !c.certified:
+		return "a caller without credentials"
+	case c.name != "":
+		return c.name
+	}
+	return "uid " + strconv.FormatUint(uint64(c.uid), 10)
+}
+
+// admin reports whether the caller may do everything. These are root, the
+// user running the daemon and the users in the admins setting.
+func (c *caller) admin() bool {
+	if c.local && (c.uid == 0 || int(c.uid) == os.Geteuid()) {
+		return true
+	}
+	return (c.local || c.certified) && c.name != "" && slices.Contains(config.Current().Access.Admins, c.name)
+}
+
+// requireAdmin fails unless the caller is an admin.
+func (c *caller) requireAdmin(action string) error {
+	if c.admin() {
+		return nil
+	}
+	return status.Errorf(codes.PermissionDenied, "%s is not allowed to %s", c, action)
+}
+
+// sees reports whether the caller may see path. Everyone but admins only sees
+// their home directory.
+func (c *caller) sees(path string) bool {
+	if c.admin() {
+		return true
+	}
+	for _, home := range c.homes {
+		if common.InTree(path, home) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPaths fails unless the caller may act on all paths. Symbolic links are
+// followed, so that they can not lead out of the home directory.
+func (c *caller) checkPaths(paths ...string) error {
+	if c.admin() {
+		return nil
+	}
+	for _, path := range paths {
+		if !filepath.IsAbs(path) || !c.sees(resolvePath(path)) {
+			return status.Errorf(codes.PermissionDenied, "%s is not allowed to access %s", c, path)
+		}
+	}
+	return nil
+}
+
+// resolvePath follows the symbolic links of the longest part of path that
+// exists.
+func resolvePath(path string) string {
+	path = filepath.Clean(path)
+	rest := ""
+	for {
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			return filepath.Join(resolved, rest)
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return filepath.Join(path, rest)
+		}
+		rest = filepath.Join(filepath.Base(path), rest)
+		path = parent
+	}
+}
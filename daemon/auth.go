@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Regis-Caelum/drive-sync/config"
+	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
+	"golang.org/x/oauth2"
+	"log"
+	"strings"
+)
+
+// logout revokes the stored credentials and forgets them. mode decides what
+// happens to the remote state: its IDs are kept for logging in to the same
+// account again, dropped, or the host folder is deleted from the remote too.
+func logout(mode pb.LOGOUT_MODE) error {
+	value := tokenValue()
+	if value == "" && unreadableToken == "" {
+		return errors.New("not logged in")
+	}
+
+	if mode == pb.LOGOUT_MODE_PURGE_REMOTE {
+		if !remoteReady() {
+			return errors.New("the remote can not be reached to purge it")
+		}
+		host := hostFolder()
+		err := retryRemote("Delete host folder", func() error {
+			return backend.Delete(host)
+		})
+		if err != nil {
+			return fmt.Errorf("unable to delete the host folder: %w", err)
+		}
+		log.Printf("Deleted host folder %s", host)
+	}
+
+	if value != "" && !isServiceAccountKey(value) {
+		err := gDriveRevoke(value)
+		if err != nil {
+			log.Printf("Unable to revoke the token, it is only removed locally: %v", err)
+		}
+	}
+
+	// Workers stop claiming jobs until the next login.
+	remoteAuthRequired.Store(true)
+	if mode != pb.LOGOUT_MODE_KEEP_STATE {
+		err := forgetRemoteState()
+		if err != nil {
+			return err
+		}
+	}
+	return updateToken(func() {
+		token.Value = ""
+		token.Subject = ""
+		unreadableToken = ""
+	})
+}
+
+// authStatus describes the connected account without writing to the remote.
+// Tokens never leave the daemon, only the time the current access token
+// expires.
+func authStatus() *pb.AuthStatus {
+	value := tokenValue()
+	status := &pb.AuthStatus{
+		LoggedIn:     value != "" && !remoteAuthRequired.Load(),
+		Connected:    backend != nil && !remoteAuthRequired.Load() && hostFolder() != "",
+		Backend:      config.Current().Backend.Name,
+		RootFolderId: rootFolder(),
+		HostFolderId: hostFolder(),
+	}
+	if status.Backend == "drive" && status.LoggedIn {
+		status.ServiceAccount = isServiceAccountKey(value)
+		status.Subject = token.GetSubject()
+		if !status.ServiceAccount {
+			status.ClientId = gDriveClientID()
+		}
+	}
+	if !status.LoggedIn {
+		if value != "" {
+			status.Error = "the stored credentials were rejected, run `dsync login` to log in again"
+		}
+		return status
+	}
+
+	var errs []string
+	tok := new(oauth2.Token)
+	if _, ok := backend.(driveBackend); ok && gDriveTokenSource != nil {
+		var err error
+		tok, err = gDriveTokenSource.Token()
+		if err != nil {
+			errs = append(errs, err.Error())
+		} else {
+			status.Scopes, err = gDriveScopes(tok.AccessToken)
+			if err != nil {
+				errs = append(errs, err.Error())
+			}
+			status.Email, err = gDriveEmail()
+			if err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	} else if err := json.Unmarshal([]byte(value), tok); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if tok != nil && !tok.Expiry.IsZero() {
+		status.Expiry = tok.Expiry.Unix()
+	}
+	status.Error = strings.Join(errs, "; ")
+	return status
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Regis-Caelum/drive-sync/config"
+	"github.com/Regis-Caelum/drive-sync/daemon/database"
+	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
+	"io"
+	"log"
+	"time"
+)
+
+// Backend is a storage target the watched trees are mirrored into. Entries are
+// addressed by IDs chosen by the backend; they are what the drive_id columns of
+// DriveRecord, Node and WatchList hold.
+type Backend interface {
+	// Name identifies the backend. It is stored with the token so that the
+	// daemon notices when it is pointed at a different target.
+	Name() string
+
+	// EnsureFolder returns the ID of the folder called name inside parentID,
+	// creating it when it does not exist yet. An empty parentID stands for
+	// the top level of the backend.
+	EnsureFolder(name, parentID, localPath string) (string, error)
+
+	// CreateFile uploads the content of the node as a new file called name
+	// inside parentID and returns the new file.
+	CreateFile(name, parentID string, f *pb.Node) (*remoteEntry, error)
+
+	// UpdateFile replaces the content of the file id with the content of the
+	// node and returns the updated file.
+	UpdateFile(id string, f *pb.Node) (*remoteEntry, error)
+
+	// Move renames the entry id to name and moves it from oldParentID to
+	// newParentID. It returns the ID of the entry afterwards, which differs
+	// from id for backends that address entries by their path.
+	Move(id, name, localPath, oldParentID, newParentID string) (string, error)
+
+	// Delete removes the entry id together with everything inside it.
+	Delete(id string) error
+
+	// Exists reports whether the entry id is still there.
+	Exists(id string) (bool, error)
+
+	// Stat returns the current state of the entry id.
+	Stat(id string) (*remoteEntry, error)
+
+	// Lookup returns the file called name inside parentID, or nil when there
+	// is none.
+	Lookup(name, parentID string) (*remoteEntry, error)
+
+	// Download writes the content of the file id from offset on to w, so that
+	// interrupted downloads can be resumed.
+	Download(id string, offset int64, w io.Writer) error
+
+	// List returns the entries inside the folder id.
+	List(id string) ([]*remoteEntry, error)
+}
+
+// remoteEntry describes an entry of a backend folder. MD5 is the hex encoded
+// checksum of the content, it is empty for folders and for backends that do
+// not know it.
+type remoteEntry struct {
+	ID           string
+	Name         string
+	IsDir        bool
+	Size         int64
+	ModifiedTime time.Time
+	MD5          string
+}
+
+// copyFrom copies a download starting at offset to w. Servers that ignore the
+// Range header send the whole content, the part before offset is skipped then.
+func copyFrom(w io.Writer, r io.Reader, offset int64, partial bool) error {
+	if offset > 0 && !partial {
+		_, err := io.CopyN(io.Discard, r, offset)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := io.Copy(w, r)
+	return err
+}
+
+const (
+	// backendRetryDelay is how long the daemon waits before it sets up a
+	// backend that failed to set up again. The delay doubles with every
+	// failed attempt up to backendMaxRetryDelay.
+	backendRetryDelay    = 5 * time.Second
+	backendMaxRetryDelay = 5 * time.Minute
+)
+
+// backend is the target all queued jobs are applied to. It is nil while no
+// backend could be set up, for instance before the first `dsync login`.
+var backend Backend
+
+var (
+	// backendRetryWait is the delay before the next attempt to set up the
+	// backend, backendRetryPending tells whether one is scheduled. Both are
+	// guarded by remoteMutex.
+	backendRetryWait    = backendRetryDelay
+	backendRetryPending bool
+)
+
+// initBackend sets up the configured backend, "drive" by default, and queues
+// everything that is not in sync with it yet. A backend that can not be set
+// up, for instance because it is briefly unreachable, is set up again later.
+// The caller holds remoteMutex for writing.
+func initBackend() {
+	name := config.Current().Backend.Name
+
+	b, err := newBackend(name)
+	if err != nil {
+		fmt.Println("cannot sync files:", err)
+		if !errors.Is(err, errNotLoggedIn) {
+			retryBackend()
+		}
+		return
+	}
+	backendRetryWait = backendRetryDelay
+
+	previous := token.GetBackend()
+	if previous == "" {
+		// Only Drive existed before the backend was recorded.
+		previous = "drive"
+	}
+	if previous != name {
+		log.Printf("Backend changed from %s to %s, uploading everything again", previous, name)
+		err = forgetRemoteState()
+		if err != nil {
+			log.Println("Error:", err)
+			return
+		}
+	}
+
+	account, err := remoteAccount(b)
+	if err != nil {
+		log.Printf("Unable to identify the %s account: %v", name, err)
+		account = token.GetAccount()
+	}
+	if token.GetAccount() != "" && account != token.GetAccount() {
+		log.Printf("Account changed from %s to %s, uploading everything again", token.GetAccount(), account)
+		err = forgetRemoteState()
+		if err != nil {
+			log.Println("Error:", err)
+			return
+		}
+	}
+	if token.GetBackend() != name || token.GetAccount() != account {
+		err = updateToken(func() {
+			token.Backend = name
+			token.Account = account
+		})
+		if err != nil {
+			log.Println("Error:", err)
+		}
+	}
+
+	backend = b
+	syncRemote()
+}
+
+// retryBackend schedules another attempt to set up the backend. Workers only
+// wait for the backend, and apart from a Drive login nothing else sets it up.
+func retryBackend() {
+	if backendRetryPending {
+		return
+	}
+	backendRetryPending = true
+	wait := backendRetryWait
+	backendRetryWait = min(2*wait, backendMaxRetryDelay)
+	log.Printf("Setting up the backend again in %s", wait)
+
+	time.AfterFunc(wait, func() {
+		remoteMutex.Lock()
+		backendRetryPending = false
+		if backend == nil {
+			initBackend()
+		}
+		remoteMutex.Unlock()
+		notifyWorkers()
+	})
+}
+
+// forgetRemoteState drops the remote IDs of the whole tree, the queued jobs and
+// the account they belong to. The next sync creates the host folder again and
+// uploads everything.
+func forgetRemoteState() error {
+	err := database.ForgetRemoteTree("/")
+	if err == nil {
+		err = database.ClearJobs()
+	}
+	if err != nil {
+		return err
+	}
+	tokenMutex.Lock()
+	token.Root = ""
+	token.Host = ""
+	token.ChangesToken = ""
+	token.Account = ""
+	tokenMutex.Unlock()
+	return nil
+}
+
+// remoteAccount identifies the account b is connected to, so that logging in
+// to another one is noticed. Only Drive tells accounts apart.
+func remoteAccount(b Backend) (string, error) {
+	if _, ok := b.(driveBackend); ok {
+		return gDriveEmail()
+	}
+	return "", nil
+}
+
+func newBackend(name string) (Backend, error) {
+	switch name {
+	case "drive":
+		return newDriveBackend()
+	case "local":
+		return newLocalBackend(config.Current().Backend.Local.Root)
+	case "s3":
+		return newS3Backend()
+	case "webdav":
+		return newWebdavBackend()
+	case "sftp":
+		return newSftpBackend()
+	}
+	return nil, fmt.Errorf("unknown backend %q", name)
+}
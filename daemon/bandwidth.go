@@ -0,0 +1,84 @@
+package main
+
+import (
+	"github.com/Regis-Caelum/drive-sync/config"
+	"golang.org/x/net/context"
+	"golang.org/x/time/rate"
+	"io"
+)
+
+// uploadLimiter and downloadLimiter are shared by all transfers, so the
+// configured limits hold for all workers together.
+var (
+	uploadLimiter   = rate.NewLimiter(rate.Inf, 0)
+	downloadLimiter = rate.NewLimiter(rate.Inf, 0)
+)
+
+// setBandwidth applies the configured limits, which take effect for running
+// transfers as well.
+func setBandwidth(b config.Bandwidth) {
+	setLimit(uploadLimiter, b.Upload)
+	setLimit(downloadLimiter, b.Download)
+}
+
+func setLimit(limiter *rate.Limiter, kib int64) {
+	if kib <= 0 {
+		limiter.SetLimit(rate.Inf)
+		return
+	}
+	bytes := kib * 1024
+	limiter.SetBurst(int(bytes))
+	limiter.SetLimit(rate.Limit(bytes))
+}
+
+// throttle waits until n bytes may be transferred and returns how many of
+// them may be transferred at once.
+func throttle(limiter *rate.Limiter, n int) int {
+	if limiter.Limit() == rate.Inf {
+		return n
+	}
+	if burst := limiter.Burst(); n > burst {
+		n = burst
+	}
+	_ = limiter.WaitN(context.Background(), n)
+	return n
+}
+
+type throttledReader struct {
+	r       io.Reader
+	limiter *rate.Limiter
+}
+
+// throttleUpload limits reading from r to the upload bandwidth.
+func throttleUpload(r io.Reader) io.Reader {
+	return &throttledReader{r: r, limiter: uploadLimiter}
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return t.r.Read(p)
+	}
+	return t.r.Read(p[:throttle(t.limiter, len(p))])
+}
+
+type throttledWriter struct {
+	w       io.Writer
+	limiter *rate.Limiter
+}
+
+// throttleDownload limits writing to w to the download bandwidth.
+func throttleDownload(w io.Writer) io.Writer {
+	return &throttledWriter{w: w, limiter: downloadLimiter}
+}
+
+func (t *throttledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		n, err := t.w.Write(p[written : written+throttle(t.limiter, len(p)-written)])
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,493 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Regis-Caelum/drive-sync/daemon/common"
+	"github.com/Regis-Caelum/drive-sync/daemon/database"
+	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
+	"google.golang.org/api/drive/v3"
+	"gorm.io/gorm"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// changesPollInterval is how often Drive is asked for remote changes.
+	changesPollInterval = 30 * time.Second
+
+	// echoWindow is how long fsnotify events for a path the poller changed
+	// are ignored, so that remote changes are not uploaded again.
+	echoWindow = 5 * time.Second
+
+	gDriveFolderMimeType = "application/vnd.google-apps.folder"
+)
+
+var (
+	echoMutex sync.Mutex
+	echoPaths = make(map[string]time.Time)
+)
+
+// suppressEcho ignores the fsnotify events of the given paths, and of
+// everything below them, for the next echoWindow.
+func suppressEcho(paths ...string) {
+	echoMutex.Lock()
+	defer echoMutex.Unlock()
+
+	until := time.Now().Add(echoWindow)
+	for _, path := range paths {
+		echoPaths[path] = until
+	}
+}
+
+// isEcho reports whether an fsnotify event for path was caused by the poller.
+func isEcho(path string) bool {
+	echoMutex.Lock()
+	defer echoMutex.Unlock()
+
+	now := time.Now()
+	for p, until := range echoPaths {
+		if now.After(until) {
+			delete(echoPaths, p)
+		}
+	}
+	for p := path; ; p = filepath.Dir(p) {
+		if _, ok := echoPaths[p]; ok {
+			return true
+		}
+		if p == filepath.Dir(p) {
+			return false
+		}
+	}
+}
+
+// isTempName reports whether name is one of the temporary files downloads and
+// pulls are written to before they are moved into place. Writing them may
+// take longer than the echo window, so their events are never handled.
+func isTempName(name string) bool {
+	return strings.HasPrefix(name, ".dsync-") || strings.HasSuffix(name, ".dsync-part")
+}
+
+// gDrivePollChanges applies the changes made on Drive, in the web UI or by
+// another machine, to the local tree. It only runs with the Drive backend.
+func gDrivePollChanges() {
+	ticker := time.NewTicker(changesPollInterval)
+	defer ticker.Stop()
+
+	for {
+		remoteMutex.RLock()
+		if _, ok := backend.(driveBackend); ok && remoteReady() {
+			err := gDriveApplyChanges()
+			if errors.Is(err, errUnauthorized) {
+				requireLogin(err)
+			} else if err != nil {
+				log.Println("Error:", err)
+			}
+		}
+		remoteMutex.RUnlock()
+		<-ticker.C
+	}
+}
+
+// gDriveApplyChanges fetches the changes since the stored page token and
+// applies them. The first call only stores the current page token, the state
+// before it is already known from the upload.
+func gDriveApplyChanges() error {
+	if token.GetChangesToken() == "" {
+		var start *drive.StartPageToken
+		err := retryRemote("Get changes token", func() (err error) {
+			start, err = gDriveService.Changes.GetStartPageToken().Do()
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		return updateToken(func() { token.ChangesToken = start.StartPageToken })
+	}
+
+	pageToken := token.GetChangesToken()
+	for pageToken != "" {
+		var list *drive.ChangeList
+		err := retryRemote("List changes", func() (err error) {
+			list, err = gDriveService.Changes.List(pageToken).
+				IncludeRemoved(true).
+				Fields("nextPageToken, newStartPageToken, changes(fileId, removed, file(parents, " + gDriveEntryFields + "))").
+				Do()
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, change := range list.Changes {
+			err = gDriveApplyChange(change)
+			if errors.Is(err, errRetryable) || errors.Is(err, errUnauthorized) {
+				// Keep the page token, the page is applied again next time.
+				return err
+			}
+			if err != nil {
+				log.Printf("Unable to apply remote change of %s: %v", change.FileId, err)
+			}
+		}
+
+		err = updateToken(func() {
+			if list.NewStartPageToken != "" {
+				token.ChangesToken = list.NewStartPageToken
+			} else {
+				token.ChangesToken = list.NextPageToken
+			}
+		})
+		if err != nil {
+			return err
+		}
+		pageToken = list.NextPageToken
+	}
+	return nil
+}
+
+// gDriveApplyChange applies a single remote change through the DriveRecord
+// mapping. Changes outside the watched directories are ignored.
+func gDriveApplyChange(change *drive.Change) error {
+	rec, err := database.GetDriveRecordByDriveID(change.FileId)
+	known := err == nil
+
+	file := change.File
+	if change.Removed || file == nil || file.Trashed {
+		if known {
+			return removeLocal(rec.GetLocalPath())
+		}
+		return nil
+	}
+
+	localPath, ok := gDriveLocalPath(file)
+	if !ok {
+		if known {
+			// The entry was moved out of the host folder.
+			return removeLocal(rec.GetLocalPath())
+		}
+		return nil
+	}
+
+	if known {
+		if localPath != rec.GetLocalPath() {
+			err = moveLocal(rec.GetLocalPath(), localPath, file.Parents[0])
+			if err != nil {
+				return err
+			}
+		}
+		if file.MimeType == gDriveFolderMimeType {
+			return nil
+		}
+		return gDriveDownload(file, localPath)
+	}
+
+	if file.MimeType == gDriveFolderMimeType {
+		return createLocalFolder(localPath, file.Id, file.Parents[0])
+	}
+	if isIgnored(localPath, false) {
+		return nil
+	}
+	if _, err = database.GetNodeByAbsolutePath(localPath); err == nil {
+		// A local file of the same name is waiting for its upload, which
+		// finds the remote file and settles the conflict.
+		return nil
+	}
+	err = gDriveDownload(file, localPath)
+	if err != nil {
+		return err
+	}
+	return trackRemoteFile(localPath, gDriveEntry(file), file.Parents[0])
+}
+
+// gDriveLocalPath maps a Drive entry to the local path it mirrors. It fails
+// for entries outside the watched directories.
+func gDriveLocalPath(file *drive.File) (string, bool) {
+	if len(file.Parents) == 0 || file.Name == "" || strings.Contains(file.Name, "/") {
+		return "", false
+	}
+
+	parentPath := "/"
+	if file.Parents[0] != hostFolder() {
+		parent, err := database.GetDriveRecordByDriveID(file.Parents[0])
+		if err != nil {
+			return "", false
+		}
+		parentPath = parent.GetLocalPath()
+	}
+
+	localPath := filepath.Join(parentPath, file.Name)
+	ancestors, err := database.GetWatchListAncestors(localPath)
+	if err != nil || len(ancestors) == 0 {
+		return "", false
+	}
+	return localPath, true
+}
+
+// gDriveDownload replaces the local file with the content of the Drive file.
+// Files with local changes that are not uploaded yet are left alone, their
+// upload notices the remote change and applies the conflict policy.
+func gDriveDownload(file *drive.File, localPath string) error {
+	if file.Md5Checksum == "" {
+		// Google Docs have no content of their own that could be downloaded.
+		return nil
+	}
+	entry := gDriveEntry(file)
+
+	node, err := database.GetNodeByAbsolutePath(localPath)
+	if err == nil {
+		if node.GetRemoteMd5() == entry.MD5 {
+			// The change is the echo of an upload from here.
+			return nil
+		}
+		if node.GetFileStatus() == pb.FILE_STATUS_MODIFIED || node.GetUploadStatus() == pb.FILE_STATUS_CONFLICT {
+			log.Printf("%s changed locally and on Drive", localPath)
+			return nil
+		}
+	} else {
+		node = nil
+	}
+
+	if sum, err := common.FileMD5(localPath); err == nil && sum == entry.MD5 {
+		if node == nil {
+			return nil
+		}
+		return database.UpdateNodeFields(node.GetId(), map[string]interface{}{
+			"remote_md5":           entry.MD5,
+			"remote_modified_time": formatRemoteTime(entry.ModifiedTime),
+		})
+	}
+	return downloadFile(node, localPath, entry, nil)
+}
+
+// trackRemoteFile records a file that was created on Drive as uploaded.
+func trackRemoteFile(localPath string, entry *remoteEntry, parentID string) error {
+	if _, err := database.GetNodeByAbsolutePath(localPath); err != nil {
+		node := &pb.Node{
+			Name:               filepath.Base(localPath),
+			FileStatus:         pb.FILE_STATUS_UNMODIFIED,
+			UploadStatus:       pb.FILE_STATUS_UPLOADED,
+			AbsolutePath:       localPath,
+			DriveId:            entry.ID,
+			RemoteMd5:          entry.MD5,
+			RemoteModifiedTime: formatRemoteTime(entry.ModifiedTime),
+		}
+		if info, err := os.Stat(localPath); err == nil {
+			setFileState(node, info)
+		}
+		err = database.CreateNode(node)
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := database.GetDriveRecordByLocalPath(localPath); err == nil {
+		// The file was uploaded from here and the change is its echo.
+		return nil
+	}
+	return database.CreateDriveRecord(&pb.DriveRecord{
+		Name:      filepath.Base(localPath),
+		LocalPath: localPath,
+		DriveId:   entry.ID,
+		ParentId:  parentID,
+	})
+}
+
+// createLocalFolder creates and watches a folder that was created on Drive.
+func createLocalFolder(localPath, driveID, parentID string) error {
+	if isIgnored(localPath, true) {
+		return nil
+	}
+
+	suppressEcho(localPath)
+	err := os.MkdirAll(localPath, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	err = watcher.Add(localPath)
+	if err != nil {
+		return err
+	}
+
+	w := &pb.WatchList{
+		Name:         filepath.Base(localPath),
+		AbsolutePath: localPath,
+		DriveId:      driveID,
+	}
+	if info, err := os.Stat(localPath); err == nil {
+		w.Inode = fileInode(info)
+	}
+	err = database.CreateWatchList(w)
+	if err != nil && !errors.Is(err, gorm.ErrDuplicatedKey) {
+		return err
+	}
+	fmt.Printf("Folder created from Drive: %s (%s)\n", localPath, driveID)
+	return database.CreateDriveRecord(&pb.DriveRecord{
+		Name:      filepath.Base(localPath),
+		LocalPath: localPath,
+		DriveId:   driveID,
+		ParentId:  parentID,
+	})
+}
+
+// removeLocal deletes a file or folder that was deleted on Drive. Entries with
+// local changes that are not uploaded yet are uploaded again instead, and the
+// watched directories themselves are never deleted. Only the recorded entries
+// are removed: ignored entries and files created since the last scan never
+// reached Drive, so they and the folders holding them are kept.
+func removeLocal(path string) error {
+	if isWatchRoot(path) {
+		log.Printf("%s was deleted on Drive, keeping the watched directory", path)
+		repairRemote(path)
+		return nil
+	}
+
+	nodes, err := database.GetNodesInTree(path)
+	if err != nil {
+		return err
+	}
+	for _, n := range nodes {
+		if n.GetFileStatus() == pb.FILE_STATUS_MODIFIED {
+			log.Printf("%s was deleted on Drive but changed locally, uploading it again", path)
+			repairRemote(path)
+			return nil
+		}
+	}
+
+	paths, err := recordedPaths(path, nodes)
+	if err != nil {
+		return err
+	}
+
+	kept := false
+	suppressEcho(paths...)
+	for _, p := range paths {
+		info, err := os.Lstat(p)
+		if err == nil && info.IsDir() {
+			_ = watcher.Remove(p)
+		}
+		if err == nil {
+			err = os.Remove(p)
+		}
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			// A folder still holding entries that were never uploaded.
+			if info != nil && info.IsDir() {
+				_ = watcher.Add(p)
+			}
+			kept = true
+			continue
+		}
+
+		err = database.DeleteNodesInTree(p)
+		if err == nil {
+			err = database.DeleteWatchListInTree(p)
+		}
+		if err == nil {
+			err = database.DeleteDriveRecordsInTree(p)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	suppressEcho(paths...)
+
+	if kept {
+		log.Printf("%s was deleted on Drive, keeping the local entries that were never uploaded", path)
+		// The folders left behind are created on Drive again once a file in
+		// them is uploaded.
+		return database.ForgetRemoteTree(path)
+	}
+	fmt.Println("Directory/File deleted on Drive:", path)
+	return nil
+}
+
+// recordedPaths returns the paths at or below path that have a Node, WatchList
+// or DriveRecord, the deepest first so that folders come after their content.
+func recordedPaths(path string, nodes []*pb.Node) ([]string, error) {
+	watchList, err := database.GetWatchListInTree(path)
+	if err != nil {
+		return nil, err
+	}
+	records, err := database.GetDriveRecordsInTree(path)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, n := range nodes {
+		seen[n.GetAbsolutePath()] = true
+	}
+	for _, w := range watchList {
+		seen[w.GetAbsolutePath()] = true
+	}
+	for _, r := range records {
+		seen[r.GetLocalPath()] = true
+	}
+
+	paths := make([]string, 0, len(seen))
+	for p := range seen {
+		paths = append(paths, p)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return strings.Count(paths[i], "/") > strings.Count(paths[j], "/")
+	})
+	return paths, nil
+}
+
+// moveLocal renames a file or folder that was renamed or moved on Drive.
+func moveLocal(oldPath, newPath, parentID string) error {
+	if isWatchRoot(oldPath) {
+		log.Printf("%s was moved on Drive to %s, keeping the watched directory", oldPath, newPath)
+		return nil
+	}
+	if common.PathExist(newPath) {
+		return fmt.Errorf("unable to move %s to %s, the target exists", oldPath, newPath)
+	}
+
+	watchList, err := database.GetWatchListInTree(oldPath)
+	if err != nil {
+		return err
+	}
+	for _, w := range watchList {
+		_ = watcher.Remove(w.GetAbsolutePath())
+	}
+
+	suppressEcho(oldPath, newPath)
+	err = os.Rename(oldPath, newPath)
+	suppressEcho(oldPath, newPath)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Directory/File moved on Drive: %s -> %s\n", oldPath, newPath)
+
+	err = database.MovePath(oldPath, newPath, parentID)
+	if err != nil {
+		return err
+	}
+
+	watchList, err = database.GetWatchListInTree(newPath)
+	if err != nil {
+		return err
+	}
+	for _, w := range watchList {
+		err = watcher.Add(w.GetAbsolutePath())
+		if err != nil {
+			log.Println("Error:", err)
+		}
+	}
+	return nil
+}
+
+// isWatchRoot reports whether path is a directory added with `dsync add dir`
+// rather than one found below it.
+func isWatchRoot(path string) bool {
+	if _, err := database.GetWatchList(path); err != nil {
+		return false
+	}
+	ancestors, err := database.GetWatchListAncestors(path)
+	return err == nil && len(ancestors) == 0
+}
@@ -1,6 +1,9 @@
 package common
 
 import (
+	"crypto/md5"
+	"encoding/hex"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,13 +14,31 @@ func PathExist(absPath string) bool {
 	return !os.IsNotExist(err)
 }
 
-func IsHiddenPath(path string) bool {
-	// Check if the path or any segment of the path starts with a dot
-	segments := strings.Split(filepath.Clean(path), string(filepath.Separator))
-	for _, segment := range segments {
-		if strings.HasPrefix(segment, ".") {
-			return true
-		}
+func IsDir(absPath string) bool {
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return false
 	}
-	return false
+	return info.IsDir()
+}
+
+// InTree reports whether path is root or lies below it.
+func InTree(path, root string) bool {
+	return path == root || root == string(filepath.Separator) ||
+		strings.HasPrefix(path, root+string(filepath.Separator))
+}
+
+// FileMD5 returns the hex encoded MD5 checksum of the file content.
+func FileMD5(absPath string) (string, error) {
+	file, err := os.Open(absPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := md5.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
 }
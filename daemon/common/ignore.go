@@ -0,0 +1,182 @@
+package common
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// IgnoreRule is a single pattern of an ignore file in gitignore syntax.
+type IgnoreRule struct {
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ParseIgnore reads the patterns of an ignore file. Like in .gitignore,
+// blank lines and lines starting with # are skipped, a leading ! negates a
+// pattern, a trailing / restricts it to directories, patterns containing a /
+// are relative to the directory of the file and ** matches any number of
+// directories. Invalid patterns are skipped.
+func ParseIgnore(r io.Reader) ([]IgnoreRule, error) {
+	var rules []IgnoreRule
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		rule, ok := parseIgnoreLine(scanner.Text())
+		if ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, scanner.Err()
+}
+
+// MatchIgnore applies the rules in order to the slash separated path rel and
+// returns whether the last matching rule ignores it. ignored is the outcome of
+// earlier rules and is returned unchanged when no rule matches.
+func MatchIgnore(rules []IgnoreRule, rel string, isDir, ignored bool) bool {
+	for _, rule := range rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.pattern.MatchString(rel) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+func parseIgnoreLine(line string) (IgnoreRule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	// Trailing spaces are dropped unless they are escaped.
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return IgnoreRule{}, false
+	}
+
+	var rule IgnoreRule
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return IgnoreRule{}, false
+	}
+
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	expr := "^(?:.*/)?"
+	if anchored {
+		expr = "^"
+	}
+	translated, ok := ignorePatternExpr(line)
+	if !ok {
+		return IgnoreRule{}, false
+	}
+	expr += translated + "$"
+
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return IgnoreRule{}, false
+	}
+	rule.pattern = pattern
+	return rule, true
+}
+
+// ignorePatternExpr translates the wildcards of a pattern to a regular
+// expression. It fails for a bracket expression that is not closed, git never
+// matches such a pattern.
+func ignorePatternExpr(p string) (string, bool) {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		atSegmentStart := i == 0 || p[i-1] == '/'
+		switch {
+		case atSegmentStart && strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case atSegmentStart && p[i:] == "**":
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		case p[i] == '[':
+			end := classEnd(p, i)
+			if end < 0 {
+				return "", false
+			}
+			b.WriteString(ignoreClassExpr(p[i+1 : end]))
+			i = end
+		case p[i] == '\\' && i+1 < len(p):
+			i++
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	return b.String(), true
+}
+
+// classEnd returns the index of the ] closing the bracket expression that
+// starts at p[start], or -1 when it is not closed. A ] right after the opening
+// bracket or its negation is part of the class, like in git.
+func classEnd(p string, start int) int {
+	i := start + 1
+	if i < len(p) && (p[i] == '!' || p[i] == '^') {
+		i++
+	}
+	if i < len(p) && p[i] == ']' {
+		i++
+	}
+	for ; i < len(p); i++ {
+		switch {
+		case p[i] == ']':
+			return i
+		case p[i] == '\\':
+			i++
+		case strings.HasPrefix(p[i:], "[:"):
+			if end := strings.Index(p[i+2:], ":]"); end >= 0 {
+				i += end + 3
+			}
+		}
+	}
+	return -1
+}
+
+// ignoreClassExpr translates the content of a bracket expression to a
+// character class. Like a wildcard, a class never matches a /.
+func ignoreClassExpr(class string) string {
+	var b strings.Builder
+	b.WriteString("[")
+	if strings.HasPrefix(class, "!") || strings.HasPrefix(class, "^") {
+		b.WriteString("^/")
+		class = class[1:]
+	}
+	for i := 0; i < len(class); i++ {
+		switch {
+		case strings.HasPrefix(class[i:], "[:"):
+			end := strings.Index(class[i+2:], ":]")
+			if end >= 0 {
+				b.WriteString(class[i : i+end+4])
+				i += end + 3
+				continue
+			}
+			b.WriteString(`\[`)
+		case class[i] == '\\' && i+1 < len(class):
+			i++
+			b.WriteString(regexp.QuoteMeta(class[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(class[i : i+1]))
+		}
+	}
+	b.WriteString("]")
+	return b.String()
+}
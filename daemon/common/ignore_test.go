@@ -0,0 +1,99 @@
+package common
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestMatchIgnore pins the translation of gitignore patterns. The expected
+// results are those of `git check-ignore` for the same pattern and path.
+func TestMatchIgnore(t *testing.T) {
+	tests := []struct {
+		patterns string
+		path     string
+		isDir    bool
+		want     bool
+	}{
+		{"*.log", "a.log", false, true},
+		{"*.log", "dir/a.log", false, true},
+		{"*.log", "a.log.txt", false, false},
+		{"/build", "build", true, true},
+		{"/build", "src/build", true, false},
+		{"build/", "build", true, true},
+		{"build/", "build", false, false},
+		{"build/", "src/build", true, true},
+		{"doc/*.txt", "doc/a.txt", false, true},
+		{"doc/*.txt", "doc/sub/a.txt", false, false},
+		{"doc/*.txt", "x/doc/a.txt", false, false},
+		{"**/foo", "foo", false, true},
+		{"**/foo", "a/b/foo", false, true},
+		{"foo/**", "foo", true, false},
+		{"foo/**", "foo/a", false, true},
+		{"foo/**", "foo/a/b", false, true},
+		{"a/**/b", "a/b", false, true},
+		{"a/**/b", "a/x/b", false, true},
+		{"a/**/b", "a/x/y/b", false, true},
+		{"a/**/b", "xa/b", false, false},
+		{"a/**/", "a/x", true, true},
+		{"a/**/", "a/x", false, false},
+		{"?.txt", "a.txt", false, true},
+		{"?.txt", "ab.txt", false, false},
+		{"d/a?b", "d/a/b", false, false},
+		{"d/a*b", "d/a/b", false, false},
+		{"[ab].c", "a.c", false, true},
+		{"[ab].c", "c.c", false, false},
+		{"[!a].c", "b.c", false, true},
+		{"[!a].c", "a.c", false, false},
+		{"[^a].c", "a.c", false, false},
+		{"d/x[!a]y", "d/x/y", false, false},
+		{"d/x[!a]y", "d/xby", false, true},
+		{"[]a].c", "].c", false, true},
+		{"[]a].c", "a.c", false, true},
+		{"[a-c]", "b", false, true},
+		{"[a-c]", "d", false, false},
+		{"[[:digit:]].txt", "1.txt", false, true},
+		{"[[:digit:]].txt", "a.txt", false, false},
+		{"foo[", "foo[", false, false},
+		{`foo\[`, "foo[", false, true},
+		{`[a\]]`, "]", false, true},
+		{"*.[ch]", "src/main.c", false, true},
+		{`\!important`, "!important", false, true},
+		{`\#note`, "#note", false, true},
+		{"# comment", "# comment", false, false},
+		{`foo\ `, "foo ", false, true},
+		{"foo  ", "foo", false, true},
+		{`\*`, "*", false, true},
+		{`\*`, "a", false, false},
+		{"*.log\n!keep.log", "keep.log", false, false},
+		{"*.log\n!keep.log", "a.log", false, true},
+		{"!keep.log\n*.log", "keep.log", false, true},
+		{"**", "a/b", false, true},
+	}
+
+	for _, test := range tests {
+		rules, err := ParseIgnore(strings.NewReader(test.patterns))
+		if err != nil {
+			t.Fatalf("ParseIgnore(%q): %v", test.patterns, err)
+		}
+		got := MatchIgnore(rules, test.path, test.isDir, false)
+		if got != test.want {
+			t.Errorf("pattern %q, path %q (dir %v): ignored = %v, want %v", test.patterns, test.path, test.isDir,
+				got, test.want)
+		}
+	}
+}
+
+// TestMatchIgnoreKeepsEarlierOutcome checks that rules which do not match
+// leave the outcome of the rules of an outer ignore file alone.
+func TestMatchIgnoreKeepsEarlierOutcome(t *testing.T) {
+	rules, err := ParseIgnore(strings.NewReader("*.log\n!keep.log\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !MatchIgnore(rules, "a.txt", false, true) {
+		t.Error("a.txt: an ignored path became included without a matching rule")
+	}
+	if MatchIgnore(rules, "keep.log", false, true) {
+		t.Error("keep.log: the negation did not include an ignored path")
+	}
+}
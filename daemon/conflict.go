@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Regis-Caelum/drive-sync/config"
+	"github.com/Regis-Caelum/drive-sync/daemon/common"
+	"github.com/Regis-Caelum/drive-sync/daemon/database"
+	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// conflictPolicies maps the names accepted by the conflict_policy setting and
+// `dsync conflicts resolve --keep` to the policies.
+var conflictPolicies = map[string]pb.CONFLICT_POLICY{
+	"keep-both":   pb.CONFLICT_POLICY_KEEP_BOTH,
+	"local-wins":  pb.CONFLICT_POLICY_LOCAL_WINS,
+	"remote-wins": pb.CONFLICT_POLICY_REMOTE_WINS,
+	"manual":      pb.CONFLICT_POLICY_MANUAL,
+}
+
+// conflictPolicy returns the policy applied when a file changed both locally
+// and remotely, read from the conflict_policy setting. Both copies are kept
+// by default.
+func conflictPolicy() pb.CONFLICT_POLICY {
+	name := config.Current().ConflictPolicy
+	if name == "" {
+		return pb.CONFLICT_POLICY_KEEP_BOTH
+	}
+	policy, ok := conflictPolicies[name]
+	if !ok {
+		log.Printf("Unknown conflict policy %q, keeping both copies", name)
+		return pb.CONFLICT_POLICY_KEEP_BOTH
+	}
+	return policy
+}
+
+// remoteChanged reports whether the remote file changed since the node was
+// last synced. Nodes synced before the remote state was recorded have nothing
+// to compare with and count as unchanged.
+func remoteChanged(f *pb.Node, remote *remoteEntry) bool {
+	if f.GetRemoteMd5() == "" && f.GetRemoteModifiedTime() == "" {
+		return false
+	}
+	if f.GetRemoteMd5() != "" && remote.MD5 != "" {
+		return f.GetRemoteMd5() != remote.MD5
+	}
+	return f.GetRemoteModifiedTime() != formatRemoteTime(remote.ModifiedTime)
+}
+
+// sameContent reports whether the local file has the content of the remote
+// file. Backends without checksums can only be compared by size.
+func sameContent(f *pb.Node, remote *remoteEntry) bool {
+	if remote.MD5 != "" {
+		sum, err := common.FileMD5(f.GetAbsolutePath())
+		return err == nil && sum == remote.MD5
+	}
+	info, err := os.Stat(f.GetAbsolutePath())
+	return err == nil && info.Size() == remote.Size
+}
+
+func formatRemoteTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// resolveConflict settles a file that changed locally and remotely since it
+// was last synced according to policy.
+func resolveConflict(f *pb.Node, rec *pb.DriveRecord, remote *remoteEntry, policy pb.CONFLICT_POLICY) error {
+	log.Printf("%s changed locally and on %s, resolving with %s", f.GetAbsolutePath(), backend.Name(), policy)
+
+	switch policy {
+	case pb.CONFLICT_POLICY_LOCAL_WINS:
+		return updateFile(f, rec.GetDriveId())
+
+	case pb.CONFLICT_POLICY_REMOTE_WINS:
+		return downloadFile(f, f.GetAbsolutePath(), remote, nil)
+
+	case pb.CONFLICT_POLICY_KEEP_BOTH:
+		// The local version is kept next to the file under a new name and
+		// uploaded from there, the file itself gets the remote version.
+		conflictPath := conflictName(f.GetAbsolutePath())
+		return downloadFile(f, f.GetAbsolutePath(), remote, func() error {
+			suppressEcho(conflictPath)
+			err := os.Rename(f.GetAbsolutePath(), conflictPath)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Conflicting local version kept as %s\n", conflictPath)
+			handleCreate(conflictPath)
+			return nil
+		})
+	}
+
+	f.UploadStatus = pb.FILE_STATUS_CONFLICT
+	f.Error = fmt.Sprintf("changed locally and on %s, resolve with `dsync conflicts resolve`", backend.Name())
+	return database.UpdateNodeFields(f.GetId(), map[string]interface{}{
+		"upload_status": f.UploadStatus,
+		"error":         f.Error,
+	})
+}
+
+// conflictName returns the name the local version of a conflicting file is
+// kept under, like report.conflict-myhost-20240131-154500.txt.
+func conflictName(path string) string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "local"
+	}
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s.conflict-%s-%s%s", strings.TrimSuffix(path, ext), hostname,
+		time.Now().Format("20060102-150405"), ext)
+}
+
+// downloadFile replaces the local file at localPath with the content of the
+// remote file and records it as synced on the node, if there is one. The
+// content is downloaded next to the file first; beforeReplace runs right
+// before it takes the place of the file.
+func downloadFile(f *pb.Node, localPath string, remote *remoteEntry, beforeReplace func() error) error {
+	temp := filepath.Join(filepath.Dir(localPath), ".dsync-"+filepath.Base(localPath))
+	suppressEcho(localPath, temp)
+	err := retryRemote("Download "+localPath, func() error {
+		dst, err := os.Create(temp)
+		if err != nil {
+			return err
+		}
+		err = backend.Download(remote.ID, 0, throttleDownload(dst))
+		if err == nil {
+			err = dst.Close()
+		} else {
+			dst.Close()
+		}
+		return err
+	})
+	if err == nil && beforeReplace != nil {
+		err = beforeReplace()
+	}
+	if err == nil {
+		suppressEcho(localPath, temp)
+		err = os.Rename(temp, localPath)
+	}
+	suppressEcho(localPath, temp)
+	if err != nil {
+		os.Remove(temp)
+		return fmt.Errorf("unable to download %s: %w", localPath, err)
+	}
+	fmt.Printf("File downloaded: %s (%s)\n", localPath, remote.ID)
+
+	if f == nil {
+		return nil
+	}
+	f.FileStatus = pb.FILE_STATUS_UNMODIFIED
+	f.UploadStatus = pb.FILE_STATUS_UPLOADED
+	f.DriveId = remote.ID
+	f.RemoteMd5 = remote.MD5
+	f.RemoteModifiedTime = formatRemoteTime(remote.ModifiedTime)
+	f.Error = ""
+	fields := map[string]interface{}{
+		"file_status":          f.FileStatus,
+		"upload_status":        f.UploadStatus,
+		"drive_id":             f.DriveId,
+		"remote_md5":           f.RemoteMd5,
+		"remote_modified_time": f.RemoteModifiedTime,
+		"error":                f.Error,
+	}
+	if info, err := os.Stat(localPath); err == nil {
+		setFileState(f, info)
+		for column, value := range fileStateFields(f) {
+			fields[column] = value
+		}
+	}
+	return database.UpdateNodeFields(f.GetId(), fields)
+}
+
+// resolveHeldConflict applies the policy chosen with `dsync conflicts resolve`
+// to a file held for manual resolution.
+func resolveHeldConflict(job *pb.Job) error {
+	f, err := database.GetNodeByAbsolutePath(job.GetPath())
+	if err != nil || f.GetUploadStatus() != pb.FILE_STATUS_CONFLICT {
+		return nil
+	}
+	rec, err := database.GetDriveRecordByLocalPath(job.GetPath())
+	if err != nil {
+		return err
+	}
+
+	var remote *remoteEntry
+	err = retryRemote("Check "+job.GetPath(), func() (err error) {
+		remote, err = backend.Stat(rec.GetDriveId())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return resolveConflict(f, rec, remote, job.GetPolicy())
+}
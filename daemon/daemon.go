@@ -3,6 +3,7 @@ package main
 import (
 	"errors"
 	"fmt"
+	"github.com/Regis-Caelum/drive-sync/config"
 	"github.com/Regis-Caelum/drive-sync/daemon/common"
 	"github.com/Regis-Caelum/drive-sync/daemon/database"
 	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
@@ -11,8 +12,13 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
+// renameWindow is how long a Rename event waits for the matching Create.
+const renameWindow = 500 * time.Millisecond
+
 var daemonChannel chan bool
 var watcher *fsnotify.Watcher
 var token *pb.OAuth2Token
@@ -21,21 +27,19 @@ func init() {
 	token = new(pb.OAuth2Token)
 	daemonChannel = make(chan bool)
 
-	err := database.DB.Transaction(func(tx *gorm.DB) error {
-		tx.First(token)
-		return nil
-	})
+	err := loadToken()
 	if err != nil {
 		fmt.Println("Error:", err)
 		return
 	}
 
-	if token.GetValue() != "" {
-		gDriveSync()
-	} else {
-		fmt.Println("cannot sync files, no drive connected")
+	err = database.ResetRunningJobs()
+	if err != nil {
+		log.Fatal(err)
 	}
 
+	initBackend()
+
 	err = initializeNodes()
 	if err != nil {
 		log.Fatal(err)
@@ -141,7 +145,7 @@ func initializeDeleteFloatingNodes() error {
 
 	for _, d := range driveRecords {
 		if !common.PathExist(d.GetLocalPath()) {
-			gDriveDeleteFromDriveRecord(d)
+			enqueueJob(&pb.Job{Action: pb.JOB_ACTION_DELETE_REMOTE, Path: d.GetLocalPath(), DriveId: d.GetDriveId()})
 			err = database.DeleteDriveRecord(d.GetId())
 			if err != nil {
 				return err
@@ -152,6 +156,57 @@ func initializeDeleteFloatingNodes() error {
 	return nil
 }
 
+// addWatchRoot adds a directory to the watch list with the given hidden
+// policy, or changes the policy of a directory that is watched already, and
+// tracks everything below it. Without a policy the one of a watched directory
+// is kept.
+func addWatchRoot(path string, policy *pb.HIDDEN_POLICY) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() || policy == nil {
+		return traverseDirHelper(path)
+	}
+
+	if w, err := database.GetWatchList(path); err == nil {
+		if isWatchRoot(path) && w.GetHiddenPolicy() != *policy {
+			w.HiddenPolicy = *policy
+			err = database.UpdateWatchList(w)
+			if err != nil {
+				return err
+			}
+		}
+	} else if ancestors, err := database.GetWatchListAncestors(path); err == nil && len(ancestors) == 0 {
+		err = database.CreateWatchList(&pb.WatchList{
+			Name:         info.Name(),
+			AbsolutePath: path,
+			HiddenPolicy: *policy,
+		})
+		if err != nil {
+			return err
+		}
+		err = watcher.Add(path)
+		if err != nil {
+			return err
+		}
+		enqueueJob(&pb.Job{Action: pb.JOB_ACTION_SYNC_FOLDER, Path: path})
+
+		files, err := os.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			err = traverseDirHelper(filepath.Join(path, file.Name()))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return traverseDirHelper(path)
+}
+
 func traverseDirHelper(dirPath string) error {
 	fileInfo, err := os.Stat(dirPath)
 	if err != nil {
@@ -159,23 +214,15 @@ func traverseDirHelper(dirPath string) error {
 		return err
 	}
 	isDir := fileInfo.IsDir()
-	if isDir && common.IsHiddenPath(dirPath) {
-		return fmt.Errorf("%s is a hidden path", dirPath)
+	if isIgnored(dirPath, isDir) {
+		return nil
 	}
 	if !isDir {
-		// Node doesn't exist, create a new one
-		node := &pb.Node{
-			Name:         fileInfo.Name(),
-			IsDir:        isDir,
-			FileStatus:   pb.FILE_STATUS_MODIFIED,
-			UploadStatus: pb.FILE_STATUS_NOT_UPLOADED,
-			AbsolutePath: dirPath,
-		}
-		err = database.CreateNode(node)
+		_, err = trackNode(dirPath, fileInfo)
 		if err != nil {
 			return err
 		}
-		gDriveSyncFile(node)
+		enqueueJob(&pb.Job{Action: pb.JOB_ACTION_SYNC_FILE, Path: dirPath})
 	} else {
 		files, err := os.ReadDir(dirPath)
 		if err != nil {
@@ -191,12 +238,22 @@ func traverseDirHelper(dirPath string) error {
 			watchList := &pb.WatchList{
 				Name:         fileInfo.Name(),
 				AbsolutePath: dirPath,
+				Inode:        fileInode(fileInfo),
 			}
 			err = database.CreateWatchList(watchList)
-			if err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				watchList, err = database.GetWatchList(dirPath)
+				if err == nil {
+					watchList.Inode = fileInode(fileInfo)
+					err = database.UpdateWatchList(watchList)
+				}
+				if err != nil {
+					log.Println("Error:", err)
+				}
+			} else if err != nil {
 				log.Println("Error:", err)
 			} else {
-				gDriveSyncFolder(watchList)
+				enqueueJob(&pb.Job{Action: pb.JOB_ACTION_SYNC_FOLDER, Path: dirPath})
 			}
 
 			for _, file := range files {
@@ -212,29 +269,91 @@ func traverseDirHelper(dirPath string) error {
 
 func handleCreate(path string) {
 	if info, err := os.Stat(path); err == nil {
+		if isIgnored(path, info.IsDir()) {
+			return
+		}
 		if info.IsDir() {
 			err = traverseDirHelper(path)
 			if err != nil {
 				log.Println("Error:", err)
 			}
 		} else {
-			node := &pb.Node{
-				Name:         info.Name(),
-				IsDir:        info.IsDir(),
-				FileStatus:   pb.FILE_STATUS_MODIFIED,
-				UploadStatus: pb.FILE_STATUS_NOT_UPLOADED,
-				AbsolutePath: path,
-			}
-			err = database.CreateNode(node)
+			_, err = trackNode(path, info)
 			if err != nil {
 				log.Println("Error:", err)
+				return
 			}
 
-			gDriveSyncFile(node)
+			enqueueJob(&pb.Job{Action: pb.JOB_ACTION_SYNC_FILE, Path: path})
 		}
 	}
 }
 
+func handleWrite(path string) {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() || isIgnored(path, false) {
+		return
+	}
+
+	node, err := database.GetNodeByAbsolutePath(path)
+	if err != nil {
+		// The file was never tracked, so treat the write as a creation.
+		handleCreate(path)
+		return
+	}
+
+	node.FileStatus = pb.FILE_STATUS_MODIFIED
+	setFileState(node, info)
+	err = database.UpdateNode(node)
+	if err != nil {
+		log.Println("Error:", err)
+		return
+	}
+
+	enqueueJob(&pb.Job{Action: pb.JOB_ACTION_SYNC_FILE, Path: path})
+}
+
+// trackNode creates the node for a file, or returns the existing one when the
+// file is already tracked.
+func trackNode(path string, info os.FileInfo) (*pb.Node, error) {
+	node := &pb.Node{
+		Name:         info.Name(),
+		IsDir:        info.IsDir(),
+		FileStatus:   pb.FILE_STATUS_MODIFIED,
+		UploadStatus: pb.FILE_STATUS_NOT_UPLOADED,
+		AbsolutePath: path,
+	}
+	setFileState(node, info)
+	err := database.CreateNode(node)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		node, err = database.GetNodeByAbsolutePath(path)
+		if err != nil {
+			return nil, err
+		}
+		setFileState(node, info)
+		return node, database.UpdateNodeFields(node.GetId(), fileStateFields(node))
+	}
+	return node, err
+}
+
+// setFileState records the inode, size and modification time of the file on
+// its node. They tell the file apart from a new one created where it was
+// moved to.
+func setFileState(node *pb.Node, info os.FileInfo) {
+	node.Inode = fileInode(info)
+	node.Size = info.Size()
+	node.ModTime = info.ModTime().UnixNano()
+}
+
+// fileStateFields returns the columns written by setFileState.
+func fileStateFields(node *pb.Node) map[string]interface{} {
+	return map[string]interface{}{
+		"inode":    node.GetInode(),
+		"size":     node.GetSize(),
+		"mod_time": node.GetModTime(),
+	}
+}
+
 func handleRemove(path string) {
 	if _, err := os.Stat(path); err != nil {
 		handleRename(path)
@@ -242,50 +361,162 @@ func handleRemove(path string) {
 }
 
 func handleRename(oldPath string) {
-	nodes, err := database.GetNodesWithPrefix("absolute_path", oldPath)
+	nodes, err := database.GetNodesInTree(oldPath)
 	if err != nil {
 		log.Println("Error:", err)
 		return
 	}
 
-	err = database.DeleteNodeWithPrefix("absolute_path", oldPath)
+	err = database.DeleteNodesInTree(oldPath)
 	if err != nil {
 		log.Println("Error:", err)
 		return
 	}
 
 	for _, n := range nodes {
-		gDriveDeleteFiles(n)
+		if n.GetDriveId() != "" {
+			enqueueJob(&pb.Job{Action: pb.JOB_ACTION_DELETE_REMOTE, Path: n.GetAbsolutePath(), DriveId: n.GetDriveId()})
+		}
 	}
 
-	err = database.DeleteDriveRecordsWithPrefix("local_path", oldPath)
+	watchList, err := database.GetWatchListInTree(oldPath)
 	if err != nil {
 		log.Println("Error:", err)
 		return
 	}
 
-	watchList, err := database.GetWatchListWithPrefix("absolute_path", oldPath)
+	err = database.DeleteWatchListInTree(oldPath)
 	if err != nil {
 		log.Println("Error:", err)
 		return
 	}
 
-	err = database.DeleteWatchListWithPrefix("absolute_path", oldPath)
+	for _, w := range watchList {
+		if w.GetDriveId() != "" {
+			enqueueJob(&pb.Job{Action: pb.JOB_ACTION_DELETE_REMOTE, Path: w.GetAbsolutePath(), DriveId: w.GetDriveId()})
+		}
+	}
+
+	err = database.DeleteDriveRecordsInTree(oldPath)
 	if err != nil {
 		log.Println("Error:", err)
 		return
 	}
 
-	for _, w := range watchList {
-		gDriveDeleteFolders(w)
+}
+
+// handleMove applies a rename or move that stayed inside a watch tree. The
+// local records of the whole subtree are rewritten right away and the Drive
+// entry is renamed and re-parented in place by the queue, so nothing has to be
+// uploaded again. An entry the move replaced is deleted from Drive, and a file
+// whose content changed since it was recorded is uploaded after the move.
+func handleMove(oldPath, newPath string) {
+	info, err := os.Stat(newPath)
+	if err != nil || isIgnored(newPath, info.IsDir()) {
+		handleRename(oldPath)
+		return
 	}
 
-	err = database.DeleteDriveRecordsWithPrefix("local_path", oldPath)
+	rec, err := database.GetDriveRecordByLocalPath(oldPath)
+	if err != nil || !sameWatchTree(oldPath, newPath) {
+		handleRename(oldPath)
+		handleCreate(newPath)
+		return
+	}
+
+	if info.IsDir() {
+		watchList, err := database.GetWatchListInTree(oldPath)
+		if err != nil {
+			log.Println("Error:", err)
+		}
+		for _, w := range watchList {
+			// The watch of the moved directory itself is already gone.
+			_ = watcher.Remove(w.GetAbsolutePath())
+		}
+	}
+
+	// Drop the records of the entry that was overwritten, so that no other
+	// entry is recorded at newPath, and delete it from Drive.
+	handleRename(newPath)
+
+	err = database.MovePath(oldPath, newPath, rec.GetParentId())
 	if err != nil {
 		log.Println("Error:", err)
 		return
 	}
 
+	enqueueJob(&pb.Job{
+		Action:   pb.JOB_ACTION_MOVE_REMOTE,
+		Path:     newPath,
+		OldPath:  oldPath,
+		DriveId:  rec.GetDriveId(),
+		ParentId: rec.GetParentId(),
+	})
+
+	if !info.IsDir() {
+		node, err := database.GetNodeByAbsolutePath(newPath)
+		if err == nil && (node.GetSize() != info.Size() || node.GetModTime() != info.ModTime().UnixNano()) {
+			node.FileStatus = pb.FILE_STATUS_MODIFIED
+			setFileState(node, info)
+			err = database.UpdateNode(node)
+			if err == nil {
+				enqueueJob(&pb.Job{Action: pb.JOB_ACTION_SYNC_FILE, Path: newPath})
+			}
+		}
+		if err != nil {
+			log.Println("Error:", err)
+		}
+	}
+
+	if info.IsDir() {
+		err = traverseDirHelper(newPath)
+		if err != nil {
+			log.Println("Error:", err)
+		}
+	}
+}
+
+// isMovedEntry reports whether the entry created at newPath is the one that
+// was renamed away from oldPath. The Create following a Rename may belong to
+// an unrelated entry when the renamed one left the watched directories.
+func isMovedEntry(oldPath, newPath string) bool {
+	info, err := os.Stat(newPath)
+	if err != nil {
+		return false
+	}
+	if info.IsDir() {
+		w, err := database.GetWatchList(oldPath)
+		return err == nil && w.GetInode() != 0 && w.GetInode() == fileInode(info)
+	}
+
+	node, err := database.GetNodeByAbsolutePath(oldPath)
+	if err != nil {
+		return false
+	}
+	if node.GetInode() != 0 && fileInode(info) != 0 {
+		return node.GetInode() == fileInode(info)
+	}
+	return node.GetModTime() != 0 && node.GetSize() == info.Size() && node.GetModTime() == info.ModTime().UnixNano()
+}
+
+// isTracked reports whether path is a known file or watched directory.
+func isTracked(path string) bool {
+	if _, err := database.GetNodeByAbsolutePath(path); err == nil {
+		return true
+	}
+	_, err := database.GetWatchList(path)
+	return err == nil
+}
+
+// sameWatchTree reports whether both paths lie below the same top-level
+// watched directory.
+func sameWatchTree(oldPath, newPath string) bool {
+	ancestors, err := database.GetWatchListAncestors(oldPath)
+	if err != nil || len(ancestors) == 0 {
+		return false
+	}
+	root := ancestors[0].GetAbsolutePath()
+	return strings.HasPrefix(newPath, root+"/")
 }
 
 func handleEventDaemon(event fsnotify.Event) {
@@ -297,12 +528,9 @@ func handleEventDaemon(event fsnotify.Event) {
 		fmt.Println("Directory/File removed:", event.Name)
 		handleRemove(event.Name)
 
-	} else if event.Op&fsnotify.Rename == fsnotify.Rename {
-		fmt.Println("Directory/File renamed or moved:", event.Name)
-		handleRename(event.Name)
-
 	} else if event.Op&fsnotify.Write == fsnotify.Write {
 		fmt.Println("Directory/File modified:", event.Name)
+		handleWrite(event.Name)
 
 	}
 }
@@ -315,12 +543,62 @@ func daemon() {
 		}
 	}(watcher)
 
+	setWorkerCount(config.Current().UploadWorkers)
+	setBandwidth(config.Current().Bandwidth)
+	go gDrivePollChanges()
 	daemonChannel <- true
 
+	// A rename shows up as a Rename event for the old path followed by a
+	// Create event for the new one. The old path is held back for a short
+	// while so that both halves can be paired into a single move.
+	var pendingRename, lastMoved string
+	renameTimer := time.NewTimer(renameWindow)
+	renameTimer.Stop()
+
 	for {
 		select {
 		case event := <-watcher.Events:
-			go handleEventDaemon(event)
+			if isEcho(event.Name) || isTempName(filepath.Base(event.Name)) {
+				continue
+			}
+			if event.Has(fsnotify.Rename) {
+				// A moved directory reports its own old path a second time.
+				if event.Name == pendingRename || event.Name == lastMoved {
+					lastMoved = ""
+					continue
+				}
+				if pendingRename != "" {
+					handleRename(pendingRename)
+					pendingRename = ""
+				}
+				if !isTracked(event.Name) {
+					continue
+				}
+				fmt.Println("Directory/File renamed or moved:", event.Name)
+				pendingRename = event.Name
+				renameTimer.Reset(renameWindow)
+				continue
+			}
+			if event.Has(fsnotify.Create) && pendingRename != "" {
+				renameTimer.Stop()
+				if isMovedEntry(pendingRename, event.Name) {
+					fmt.Printf("Directory/File moved: %s -> %s\n", pendingRename, event.Name)
+					if common.IsDir(event.Name) {
+						lastMoved = pendingRename
+					}
+					handleMove(pendingRename, event.Name)
+					pendingRename = ""
+					continue
+				}
+				handleRename(pendingRename)
+				pendingRename = ""
+			}
+			handleEventDaemon(event)
+		case <-renameTimer.C:
+			if pendingRename != "" {
+				handleRename(pendingRename)
+				pendingRename = ""
+			}
 		case err := <-watcher.Errors:
 			fmt.Println("Error:", err)
 		}
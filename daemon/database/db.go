@@ -3,30 +3,33 @@ package database
 import (
 	"errors"
 	"fmt"
+	"github.com/Regis-Caelum/drive-sync/config"
 	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
 	"google.golang.org/protobuf/proto"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"log"
-	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 )
 
 var (
 	DB *gorm.DB
+
+	jobMutex sync.Mutex
 )
 
 func init() {
 	var err error
-	var dbPath = os.Getenv("DEBUG_MODE")
-
-	if dbPath == "true" {
-		dbPath = "./daemon/database/database.sqlite"
-	} else {
-		dbPath = "/var/lib/dsync/database.sqlite"
-	}
+	dbPath := config.Current().Database
 
 	// Open the database connection
-	DB, err = gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+	// Upload workers write concurrently, so wait for locks instead of failing.
+	// Transactions take the write lock up front, a deferred transaction that
+	// reads before it writes fails at once when another one holds the lock.
+	DB, err = gorm.Open(sqlite.Open(dbPath+"?_busy_timeout=5000&_txlock=immediate"), &gorm.Config{})
 	if err != nil {
 		log.Fatal("failed to connect database:", err, dbPath)
 	}
@@ -44,7 +47,8 @@ func init() {
 		&pb.Node{},
 		&pb.WatchList{},
 		&pb.OAuth2Token{},
-		&pb.DriveRecord{})
+		&pb.DriveRecord{},
+		&pb.Job{})
 	if err != nil {
 		fmt.Println("Error:", err)
 	}
@@ -108,13 +112,13 @@ func CreateNode(node *pb.Node) error {
 	return DB.Transaction(func(tx *gorm.DB) error {
 		var existingNode pb.Node
 
-		if err := tx.Where("absolute_path = ?", existingNode.GetAbsolutePath()).First(&existingNode).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		if err := tx.Where("absolute_path = ?", node.GetAbsolutePath()).First(&existingNode).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 			return err
 		}
 
 		if existingNode.GetId() != 0 {
 			fmt.Println("Record already exists with absolute_path:", node.GetAbsolutePath())
-			return fmt.Errorf("record already exists")
+			return gorm.ErrDuplicatedKey
 		}
 
 		return tx.Create(node).Error
@@ -147,6 +151,14 @@ func UpdateNode(node *pb.Node) error {
 	})
 }
 
+// UpdateNodeFields updates only the given columns of a Node record, leaving
+// changes made to the other columns in the meantime untouched.
+func UpdateNodeFields(id int32, fields map[string]interface{}) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		return tx.Model(&pb.Node{}).Where("id = ?", id).Updates(fields).Error
+	})
+}
+
 // DeleteNode deletes a Node record by ID in a transaction.
 func DeleteNode(id int32) error {
 	return DB.Transaction(func(tx *gorm.DB) error {
@@ -174,6 +186,13 @@ func ListAllNodes() ([]*pb.Node, error) {
 	return nodes, err
 }
 
+// GetNodesByUploadStatus returns the nodes with the given upload status.
+func GetNodesByUploadStatus(status pb.FILE_STATUS) ([]*pb.Node, error) {
+	var nodes []*pb.Node
+	err := DB.Where("upload_status = ?", status).Order("absolute_path").Find(&nodes).Error
+	return nodes, err
+}
+
 // CRUD for WatchList
 
 // CreateWatchList creates a new WatchList record in a transaction.
@@ -317,6 +336,15 @@ func GetDriveRecordByLocalPath(path string) (*pb.DriveRecord, error) {
 	return &record, err
 }
 
+// GetDriveRecordByDriveID retrieves the DriveRecord record of a remote entry.
+func GetDriveRecordByDriveID(driveID string) (*pb.DriveRecord, error) {
+	var record pb.DriveRecord
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		return tx.Where("drive_id = ?", driveID).First(&record).Error
+	})
+	return &record, err
+}
+
 // UpdateDriveRecord updates an existing DriveRecord record in a transaction.
 func UpdateDriveRecord(record *pb.DriveRecord) error {
 	return DB.Transaction(func(tx *gorm.DB) error {
@@ -396,3 +424,277 @@ func GetDriveRecordsWithPrefix(columnName, prefix string) ([]*pb.DriveRecord, er
 	}
 	return result, nil
 }
+
+// CRUD for Job
+
+// EnqueueJob adds a job to the queue unless an identical job for the same path
+// is already waiting. It reports whether a new job was stored.
+func EnqueueJob(job *pb.Job) (bool, error) {
+	jobMutex.Lock()
+	defer jobMutex.Unlock()
+
+	created := false
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var existingJob pb.Job
+
+		err := tx.Where("path = ? AND action = ? AND status = ?", job.GetPath(), job.GetAction(), pb.JOB_STATUS_PENDING).
+			First(&existingJob).Error
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		job.Status = pb.JOB_STATUS_PENDING
+		created = true
+		return tx.Create(job).Error
+	})
+	return created, err
+}
+
+// ClaimJob marks the oldest runnable job as running and returns it. A job is
+// runnable when it is due and no earlier job for the same path is still
+// queued or running, so the changes to one path are applied in order. It
+// returns nil when nothing is runnable.
+func ClaimJob() (*pb.Job, error) {
+	jobMutex.Lock()
+	defer jobMutex.Unlock()
+
+	var jobs []*pb.Job
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("status = ? AND not_before <= ?", pb.JOB_STATUS_PENDING, time.Now().Unix()).
+			Where("NOT EXISTS (SELECT 1 FROM jobs AS earlier WHERE earlier.path = jobs.path AND earlier.id < jobs.id)").
+			Order("id").Limit(1).Find(&jobs).Error
+		if err != nil || len(jobs) == 0 {
+			return err
+		}
+
+		jobs[0].Status = pb.JOB_STATUS_RUNNING
+		return tx.Save(jobs[0]).Error
+	})
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+	return jobs[0], nil
+}
+
+// CompleteJob removes a finished job from the queue.
+func CompleteJob(id int32) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		return tx.Delete(&pb.Job{}, id).Error
+	})
+}
+
+// RetryJob puts a failed job back in the queue, to be run again after delay.
+func RetryJob(job *pb.Job, cause error, delay time.Duration) error {
+	job.Status = pb.JOB_STATUS_PENDING
+	job.Attempts++
+	job.Error = cause.Error()
+	job.NotBefore = time.Now().Add(delay).Unix()
+	return DB.Transaction(func(tx *gorm.DB) error {
+		return tx.Save(job).Error
+	})
+}
+
+// ResetRunningJobs makes the jobs that were running when the daemon stopped
+// runnable again.
+func ResetRunningJobs() error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		return tx.Model(&pb.Job{}).Where("status = ?", pb.JOB_STATUS_RUNNING).
+			Update("status", pb.JOB_STATUS_PENDING).Error
+	})
+}
+
+// ClearJobs removes every job from the queue.
+func ClearJobs() error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		return tx.Where("1 = 1").Delete(&pb.Job{}).Error
+	})
+}
+
+// ListAllJobs retrieves all Job records in a transaction.
+func ListAllJobs() ([]*pb.Job, error) {
+	var jobs []*pb.Job
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		return tx.Order("id").Find(&jobs).Error
+	})
+	return jobs, err
+}
+
+// GetWatchListAncestors returns the WatchList records whose directory contains
+// the given path, ordered from the outermost directory inwards.
+func GetWatchListAncestors(path string) ([]*pb.WatchList, error) {
+	var result []*pb.WatchList
+	err := DB.Where("substr(?, 1, length(absolute_path) + 1) = absolute_path || '/'", path).
+		Order("length(absolute_path)").Find(&result).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve ancestors of %s: %w", path, err)
+	}
+	return result, nil
+}
+
+// MovePath rewrites every Node, WatchList and DriveRecord at or below oldPath
+// so that it lives below newPath, and renames the moved entry itself.
+func MovePath(oldPath, newPath, newParentID string) error {
+	name := filepath.Base(newPath)
+	subtree := treePattern(oldPath)
+	rewrite := func(column string) interface{} {
+		return gorm.Expr("? || substr("+column+", length(?) + 1)", newPath, oldPath)
+	}
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&pb.Node{}).Where(inTree("absolute_path"), oldPath, subtree).
+			Update("absolute_path", rewrite("absolute_path")).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&pb.WatchList{}).Where(inTree("absolute_path"), oldPath, subtree).
+			Update("absolute_path", rewrite("absolute_path")).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&pb.DriveRecord{}).Where(inTree("local_path"), oldPath, subtree).
+			Update("local_path", rewrite("local_path")).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&pb.Node{}).Where("absolute_path = ?", newPath).Update("name", name).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&pb.WatchList{}).Where("absolute_path = ?", newPath).Update("name", name).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&pb.DriveRecord{}).Where("local_path = ?", newPath).
+			Updates(map[string]interface{}{"name": name, "parent_id": newParentID}).Error
+	})
+}
+
+// GetNodesInTree returns the Node records at or below path.
+func GetNodesInTree(path string) ([]*pb.Node, error) {
+	var result []*pb.Node
+	err := DB.Where(inTree("absolute_path"), path, treePattern(path)).Find(&result).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve records below %s: %w", path, err)
+	}
+	return result, nil
+}
+
+// DeleteNodesInTree deletes the Node records at or below path.
+func DeleteNodesInTree(path string) error {
+	err := DB.Where(inTree("absolute_path"), path, treePattern(path)).Delete(&pb.Node{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete records below %s: %w", path, err)
+	}
+	return nil
+}
+
+// GetWatchListInTree returns the WatchList records at or below path.
+func GetWatchListInTree(path string) ([]*pb.WatchList, error) {
+	var result []*pb.WatchList
+	err := DB.Where(inTree("absolute_path"), path, treePattern(path)).Find(&result).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve records below %s: %w", path, err)
+	}
+	return result, nil
+}
+
+// DeleteWatchListInTree deletes the WatchList records at or below path.
+func DeleteWatchListInTree(path string) error {
+	err := DB.Where(inTree("absolute_path"), path, treePattern(path)).Delete(&pb.WatchList{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete records below %s: %w", path, err)
+	}
+	return nil
+}
+
+// GetDriveRecordsInTree returns the DriveRecord records at or below path.
+func GetDriveRecordsInTree(path string) ([]*pb.DriveRecord, error) {
+	var result []*pb.DriveRecord
+	err := DB.Where(inTree("local_path"), path, treePattern(path)).Find(&result).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve records below %s: %w", path, err)
+	}
+	return result, nil
+}
+
+// DeleteDriveRecordsInTree deletes the DriveRecord records at or below path.
+func DeleteDriveRecordsInTree(path string) error {
+	err := DB.Where(inTree("local_path"), path, treePattern(path)).Delete(&pb.DriveRecord{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete records below %s: %w", path, err)
+	}
+	return nil
+}
+
+// ForgetRemoteTree drops the Drive state recorded at or below path, so that
+// everything there is created and uploaded again.
+func ForgetRemoteTree(path string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where(inTree("local_path"), path, treePattern(path)).Delete(&pb.DriveRecord{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&pb.WatchList{}).Where(inTree("absolute_path"), path, treePattern(path)).
+			Update("drive_id", "").Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&pb.Node{}).Where(inTree("absolute_path"), path, treePattern(path)).
+			Updates(map[string]interface{}{
+				"drive_id":        "",
+				"file_status":     pb.FILE_STATUS_MODIFIED,
+				"upload_status":   pb.FILE_STATUS_NOT_UPLOADED,
+				"upload_session":  "",
+				"upload_offset":   0,
+				"upload_size":     0,
+				"upload_mod_time": 0,
+			}).Error
+	})
+}
+
+// RewriteRemoteIDs replaces the remote ID oldID, and every ID below it, with
+// newID. Backends that address entries by path change the IDs of a whole
+// subtree when a folder is moved.
+func RewriteRemoteIDs(oldID, newID string) error {
+	subtree := treePattern(oldID)
+	rewrite := func(column string) interface{} {
+		return gorm.Expr("? || substr("+column+", length(?) + 1)", newID, oldID)
+	}
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&pb.Node{}, &pb.WatchList{}, &pb.DriveRecord{}, &pb.Job{}} {
+			err := tx.Model(model).Where(inTree("drive_id"), oldID, subtree).
+				Update("drive_id", rewrite("drive_id")).Error
+			if err != nil {
+				return err
+			}
+		}
+		for _, model := range []interface{}{&pb.DriveRecord{}, &pb.Job{}} {
+			err := tx.Model(model).Where(inTree("parent_id"), oldID, subtree).
+				Update("parent_id", rewrite("parent_id")).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// inTree builds a condition matching a path column equal to a path or lying
+// below it. It expects the path and its escaped subtree pattern as arguments.
+func inTree(column string) string {
+	return fmt.Sprintf("(%s = ? OR %s LIKE ? ESCAPE '\\')", column, column)
+}
+
+// treePattern returns the LIKE pattern matching everything below path.
+func treePattern(path string) string {
+	return escapeLike(strings.TrimSuffix(path, "/")) + "/%"
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
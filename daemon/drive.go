@@ -3,278 +3,461 @@ package main
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/Regis-Caelum/drive-sync/daemon/database"
 	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
 	"golang.org/x/net/context"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 	"io"
 	"log"
 	"mime"
 	"net/http"
-	"os"
+	"net/url"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 )
 
 var gDriveClient *http.Client
 var gDriveService *drive.Service
 
-func gDriveSync() {
+// gDriveTokenSource hands out the access tokens of gDriveClient and refreshes
+// them when they expire.
+var gDriveTokenSource oauth2.TokenSource
+
+// gDriveBuiltinClient is the client secret of the OAuth client used unless
+// `dsync login --client-secret` named another one.
+const gDriveBuiltinClient = "eyJpbnN0YWxsZWQiOnsiY2xpZW50X2lkIjoiNjU5OTE0NDgzNTUwLXBuNW1icTliN21ibmI2cDFzaWNzM3FwMzU3azRsY3FiLmFwcHMuZ29vZ2xldXNlcmNvbnRlbnQuY29tIiwicHJvamVjdF9pZCI6ImRzeW5jLTQzMzMyMSIsImF1dGhfdXJpIjoiaHR0cHM6Ly9hY2NvdW50cy5nb29nbGUuY29tL28vb2F1dGgyL2F1dGgiLCJ0b2tlbl91cmkiOiJodHRwczovL29hdXRoMi5nb29nbGVhcGlzLmNvbS90b2tlbiIsImF1dGhfcHJvdmlkZXJfeDUwOV9jZXJ0X3VybCI6Imh0dHBzOi8vd3d3Lmdvb2dsZWFwaXMuY29tL29hdXRoMi92MS9jZXJ0cyIsImNsaWVudF9zZWNyZXQiOiJHT0NTUFgtR1UzeTI2b3dvOUF5TE01bFVPTFIzbkFESjB2dCIsInJlZGlyZWN0X3VyaXMiOlsiaHR0cDovL2xvY2FsaG9zdCJdfX0="
+
+// errNotLoggedIn is returned while there is no token to set Drive up with.
+// Only `dsync login` can change that.
+var errNotLoggedIn = errors.New("no drive connected")
+
+// gDriveTokenInfoURL describes an access token, including its scopes.
+const gDriveTokenInfoURL = "https://oauth2.googleapis.com/tokeninfo"
+
+// gDriveRevokeURL revokes a token together with the grant it belongs to.
+const gDriveRevokeURL = "https://oauth2.googleapis.com/revoke"
+
+// gDriveEntryFields are the file fields gDriveEntry needs.
+const gDriveEntryFields = "id, name, mimeType, size, modifiedTime, md5Checksum, trashed"
+
+// driveBackend mirrors the watched trees into Google Drive. Entries are
+// addressed by their Drive file IDs. Requests made through the backend use
+// the service it was set up with, so that a pull can go on with it while a
+// login replaces gDriveService.
+type driveBackend struct {
+	service *drive.Service
+}
+
+// newDriveBackend sets up the Drive client from the stored token, which is
+// either an OAuth token or the key of a service account.
+func newDriveBackend() (Backend, error) {
+	value := tokenValue()
+	if value == "" {
+		return nil, errNotLoggedIn
+	}
+
 	ctx := context.Background()
 
-	hashedData := "eyJpbnN0YWxsZWQiOnsiY2xpZW50X2lkIjoiNjU5OTE0NDgzNTUwLXBuNW1icTliN21ibmI2cDFzaWNzM3FwMzU3azRsY3FiLmFwcHMuZ29vZ2xldXNlcmNvbnRlbnQuY29tIiwicHJvamVjdF9pZCI6ImRzeW5jLTQzMzMyMSIsImF1dGhfdXJpIjoiaHR0cHM6Ly9hY2NvdW50cy5nb29nbGUuY29tL28vb2F1dGgyL2F1dGgiLCJ0b2tlbl91cmkiOiJodHRwczovL29hdXRoMi5nb29nbGVhcGlzLmNvbS90b2tlbiIsImF1dGhfcHJvdmlkZXJfeDUwOV9jZXJ0X3VybCI6Imh0dHBzOi8vd3d3Lmdvb2dsZWFwaXMuY29tL29hdXRoMi92MS9jZXJ0cyIsImNsaWVudF9zZWNyZXQiOiJHT0NTUFgtR1UzeTI2b3dvOUF5TE01bFVPTFIzbkFESjB2dCIsInJlZGlyZWN0X3VyaXMiOlsiaHR0cDovL2xvY2FsaG9zdCJdfX0="
+	var err error
+	if isServiceAccountKey(value) {
+		gDriveTokenSource, err = gDriveServiceAccountTokenSource(ctx)
+	} else {
+		gDriveTokenSource, err = gDriveUserTokenSource(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get google drive client: %w", err)
+	}
+	gDriveClient = oauth2.NewClient(ctx, gDriveTokenSource)
 
-	// Unhash (decode) the data
-	b, err := base64.URLEncoding.DecodeString(hashedData)
+	gDriveService, err = drive.NewService(ctx, option.WithHTTPClient(gDriveClient))
 	if err != nil {
-		log.Fatalf("Unable to read client secret file: %v", err)
+		return nil, fmt.Errorf("unable to retrieve Drive client: %w", err)
 	}
+	return driveBackend{service: gDriveService}, nil
+}
 
-	config, err := google.ConfigFromJSON(b, drive.DriveScope)
+func (driveBackend) Name() string {
+	return "drive"
+}
+
+func (driveBackend) EnsureFolder(name, parentID, localPath string) (string, error) {
+	var parents []string
+	if parentID != "" {
+		parents = []string{parentID}
+	}
+	folder, err := gDriveCreateFolder(name, parents, localPath)
 	if err != nil {
-		log.Fatalf("Unable to parse client secret file to config: %v", err)
+		return "", err
 	}
+	return folder.Id, nil
+}
 
-	gDriveClient, err = gDriveGetClient(config)
+func (driveBackend) CreateFile(name, parentID string, f *pb.Node) (*remoteEntry, error) {
+	file, err := gDriveCreateFile(name, []string{parentID}, f.GetAbsolutePath(), f)
 	if err != nil {
-		fmt.Printf("Unable to get google drive client: %v", err)
-		return
+		return nil, err
 	}
+	return gDriveEntry(file), nil
+}
 
-	gDriveService, err = drive.NewService(ctx, option.WithHTTPClient(gDriveClient))
+func (driveBackend) UpdateFile(id string, f *pb.Node) (*remoteEntry, error) {
+	file, err := gDriveUpdateFile(id, f)
 	if err != nil {
-		log.Fatalf("Unable to retrieve Drive client: %v", err)
+		return nil, err
 	}
+	return gDriveEntry(file), nil
+}
 
-	if token.GetRoot() == "" {
-		createdRootFolder, err := gDriveCreateFolder("Computers", []string{}, "")
-		if err != nil {
-			log.Fatalf("Unable to create root folder: %v", err)
-		}
+func (driveBackend) Move(id, name, localPath, oldParentID, newParentID string) (string, error) {
+	file, err := gDriveMoveFile(id, name, localPath, oldParentID, newParentID)
+	if err != nil {
+		return "", err
+	}
+	return file.Id, nil
+}
 
-		tx, err := database.GetTx()
-		log.Println("Transaction started")
-		if err != nil {
-			fmt.Printf("Unable to get transaction: %v", err)
-			return
-		}
+func (b driveBackend) Delete(id string) error {
+	return b.service.Files.Delete(id).Context(context.Background()).Do()
+}
 
-		token.Root = createdRootFolder.Id
-		tx.Save(token)
-		database.CommitTx(tx)
-		log.Println("Transaction Ended")
-		database.RollbackTx(tx)
+// Exists treats trashed entries as gone, they are deleted for good after a
+// while.
+func (b driveBackend) Exists(id string) (bool, error) {
+	file, err := b.service.Files.Get(id).Fields("id, trashed").Do()
+	if errors.Is(classifyError(err), errRemoteNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
 	}
+	return !file.Trashed, nil
+}
 
-	if token.GetHost() == "" {
-		hostname, err := os.Hostname()
-		if err != nil {
-			log.Fatalf("Unable to get hostname: %v", err)
-		}
+// Stat treats trashed entries as missing, see Exists.
+func (b driveBackend) Stat(id string) (*remoteEntry, error) {
+	file, err := b.service.Files.Get(id).Fields(gDriveEntryFields).Do()
+	if err != nil {
+		return nil, err
+	}
+	if file.Trashed {
+		return nil, fmt.Errorf("%w: %s is in the trash", errRemoteNotFound, id)
+	}
+	return gDriveEntry(file), nil
+}
 
-		createdHostFolder, err := gDriveCreateFolder(hostname, []string{token.GetRoot()}, "")
-		if err != nil {
-			log.Fatalf("Unable to create host folder: %v", err)
-		}
+func (b driveBackend) Lookup(name, parentID string) (*remoteEntry, error) {
+	query := fmt.Sprintf("name = '%s' and '%s' in parents and trashed = false and mimeType != '%s'",
+		gDriveQuote(name), parentID, gDriveFolderMimeType)
+	r, err := b.service.Files.List().Q(query).Fields("files(" + gDriveEntryFields + ")").Do()
+	if err != nil {
+		return nil, err
+	}
+	if len(r.Files) == 0 {
+		return nil, nil
+	}
+	return gDriveEntry(r.Files[0]), nil
+}
 
-		token.Host = createdHostFolder.Id
+func (b driveBackend) Download(id string, offset int64, w io.Writer) error {
+	call := b.service.Files.Get(id)
+	if offset > 0 {
+		call.Header().Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	res, err := call.Download()
+	if err != nil {
+		return err
+	}
+	defer googleapi.CloseBody(res)
 
-		tx, err := database.GetTx()
-		log.Println("Transaction started")
-		if err != nil {
-			fmt.Printf("Unable to get transaction: %v", err)
-			return
-		}
+	return copyFrom(w, res.Body, offset, res.StatusCode == http.StatusPartialContent)
+}
+
+func (b driveBackend) Revisions(id string) ([]*remoteRevision, error) {
+	var revisions []*remoteRevision
+	err := b.service.Revisions.List(id).
+		Fields("nextPageToken, revisions(id, modifiedTime, size, md5Checksum, keepForever)").
+		Pages(context.Background(), func(list *drive.RevisionList) error {
+			for _, r := range list.Revisions {
+				modified, _ := time.Parse(time.RFC3339, r.ModifiedTime)
+				revisions = append(revisions, &remoteRevision{
+					ID:           r.Id,
+					ModifiedTime: modified,
+					Size:         r.Size,
+					MD5:          r.Md5Checksum,
+					KeepForever:  r.KeepForever,
+				})
+			}
+			return nil
+		})
+	return revisions, err
+}
 
-		tx.Save(token)
-		database.CommitTx(tx)
-		log.Println("Transaction Ended")
-		database.RollbackTx(tx)
+func (b driveBackend) DownloadRevision(id, revisionID string, offset int64, w io.Writer) error {
+	call := b.service.Revisions.Get(id, revisionID)
+	if offset > 0 {
+		call.Header().Set("Range", fmt.Sprintf("bytes=%d-", offset))
 	}
+	res, err := call.Download()
+	if err != nil {
+		return err
+	}
+	defer googleapi.CloseBody(res)
 
-	gDriveSyncFolders()
-	gDriveSyncFiles()
+	return copyFrom(w, res.Body, offset, res.StatusCode == http.StatusPartialContent)
+}
 
+// KeepHeadRevision sets keepForever on the current revision. Drive purges
+// other revisions of binary files after 30 days, and keeps at most 200
+// revisions of a file forever.
+func (b driveBackend) KeepHeadRevision(id string) error {
+	file, err := b.service.Files.Get(id).Fields("headRevisionId").Do()
+	if err != nil {
+		return err
+	}
+	_, err = b.service.Revisions.Update(id, file.HeadRevisionId, &drive.Revision{KeepForever: true}).
+		Fields("id").Do()
+	return err
 }
 
-func gDriveSyncFolders() {
-	fmt.Println("Syncing Folders:")
-	watchList, _ := database.ListAllWatchLists()
-	if len(watchList) != 0 {
-		for _, w := range watchList {
-			_, err := database.GetDriveRecordByLocalPath(w.GetAbsolutePath())
-			if err != nil {
-				gDriveSyncFolder(w)
+func (b driveBackend) List(id string) ([]*remoteEntry, error) {
+	query := fmt.Sprintf("'%s' in parents and trashed = false", id)
+	var entries []*remoteEntry
+	err := b.service.Files.List().
+		Q(query).
+		Fields("nextPageToken, files("+gDriveEntryFields+")").
+		Pages(context.Background(), func(list *drive.FileList) error {
+			for _, file := range list.Files {
+				entries = append(entries, gDriveEntry(file))
 			}
-		}
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list %s: %w", id, err)
 	}
+	return entries, nil
 }
 
-func gDriveSyncFolder(w *pb.WatchList) {
-	descPath := ""
-	pathParts := strings.Split(w.GetAbsolutePath(), "/")
-	currentParentID := token.GetHost()
-	for _, part := range pathParts {
-		if part == "" {
-			continue
-		}
-		descPath += "/" + part
-		if rec, err := database.GetDriveRecordByLocalPath(descPath); err == nil {
-			currentParentID = rec.DriveId
-			continue
-		}
-		folderID, err := gDriveCreateFolder(part, []string{currentParentID}, descPath)
-		if err != nil {
-			fmt.Printf("Unable to create folder: %v", err)
-			continue
-		}
-		log.Printf("Host folder created: %s (%s)\n", folderID.Name, folderID.Id)
-		err = database.CreateDriveRecord(&pb.DriveRecord{
-			Name:      part,
-			LocalPath: descPath,
-			DriveId:   folderID.Id,
-			ParentId:  currentParentID,
-		})
-		if err != nil {
-			fmt.Printf("Unable to update watch list: %v", err)
-		}
-		currentParentID = folderID.Id
+// gDriveEntry converts a Drive file fetched with gDriveEntryFields.
+func gDriveEntry(file *drive.File) *remoteEntry {
+	modified, _ := time.Parse(time.RFC3339, file.ModifiedTime)
+	return &remoteEntry{
+		ID:           file.Id,
+		Name:         file.Name,
+		IsDir:        file.MimeType == gDriveFolderMimeType,
+		Size:         file.Size,
+		ModifiedTime: modified,
+		MD5:          file.Md5Checksum,
 	}
-	w.DriveId = currentParentID
-	err := database.UpdateWatchList(w)
+}
+
+// gDriveQuote escapes a value for a string literal of a Drive query.
+func gDriveQuote(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+}
+
+// gDriveOAuthClient returns the client secret JSON of the OAuth client the
+// token was issued to.
+func gDriveOAuthClient() ([]byte, error) {
+	if token.GetClient() != "" {
+		return []byte(token.GetClient()), nil
+	}
+	b, err := base64.URLEncoding.DecodeString(gDriveBuiltinClient)
 	if err != nil {
-		fmt.Printf("Unable to update watch list: %v", err)
+		return nil, fmt.Errorf("unable to read client secret file: %w", err)
 	}
+	return b, nil
+}
 
+// gDriveClientID returns the ID of the OAuth client the token was issued to.
+func gDriveClientID() string {
+	b, err := gDriveOAuthClient()
+	if err != nil {
+		return ""
+	}
+	config, err := google.ConfigFromJSON(b)
+	if err != nil {
+		return ""
+	}
+	return config.ClientID
 }
 
-func gDriveSyncFiles() {
-	fmt.Println("Syncing Files:")
-	fileNodes, _ := database.ListAllNodes()
-	if len(fileNodes) != 0 {
-		for _, f := range fileNodes {
-			if f.GetUploadStatus() == pb.FILE_STATUS_NOT_UPLOADED || f.GetFileStatus() == pb.FILE_STATUS_MODIFIED {
-				gDriveSyncFile(f)
-			}
-		}
+// isServiceAccountKey reports whether value is the JSON key of a service
+// account rather than an OAuth token.
+func isServiceAccountKey(value string) bool {
+	var key struct {
+		Type string `json:"type"`
 	}
+	return json.Unmarshal([]byte(value), &key) == nil && key.Type == "service_account"
 }
 
-func gDriveSyncFile(f *pb.Node) {
-	descPath := ""
-	pathParts := strings.Split(f.GetAbsolutePath(), "/")
-	currentParentID := token.GetHost()
-	for i, part := range pathParts {
-		if part == "" {
-			continue
-		}
-		descPath += "/" + part
-		if rec, err := database.GetDriveRecordByLocalPath(descPath); err == nil {
-			currentParentID = rec.DriveId
-			continue
-		}
-		if i == len(pathParts)-1 {
-			localFile, err := os.Open(f.GetAbsolutePath())
-			if err != nil {
-				log.Fatalf("Unable to open local file: %v", err)
-			}
-			fileID, err := gDriveCreateFile(part, []string{currentParentID}, descPath, localFile)
-			_ = localFile.Close()
-			if err != nil {
-				fmt.Printf("Unable to create file: %v", err)
-				continue
-			}
-			f.DriveId = fileID.Id
-			f.FileStatus = pb.FILE_STATUS_UNMODIFIED
-			f.UploadStatus = pb.FILE_STATUS_UPLOADED
-			err = database.UpdateNode(f)
-			if err != nil {
-				fmt.Printf("Unable to update watch list: %v", err)
-			}
-			err = database.CreateDriveRecord(&pb.DriveRecord{
-				Name:      part,
-				LocalPath: descPath,
-				DriveId:   fileID.Id,
-				ParentId:  currentParentID,
-			})
-			if err != nil {
-				fmt.Printf("Unable to update watch list: %v", err)
-			}
-			continue
-		}
-		folderID, err := gDriveCreateFolder(part, []string{currentParentID}, descPath)
+// checkCredentials rejects a token the daemon would not be able to use, before
+// it replaces the stored one.
+func checkCredentials(t *pb.OAuth2Token) error {
+	if t.GetClient() != "" {
+		_, err := google.ConfigFromJSON([]byte(t.GetClient()))
 		if err != nil {
-			fmt.Printf("Unable to create folder: %v", err)
-			continue
+			return fmt.Errorf("invalid client secret: %w", err)
 		}
-		log.Printf("Host folder created: %s (%s)\n", folderID.Name, folderID.Id)
-		err = database.CreateDriveRecord(&pb.DriveRecord{
-			Name:      part,
-			LocalPath: descPath,
-			DriveId:   folderID.Id,
-			ParentId:  currentParentID,
-		})
+	}
+	if isServiceAccountKey(t.GetValue()) {
+		config, err := google.JWTConfigFromJSON([]byte(t.GetValue()))
 		if err != nil {
-			fmt.Printf("Unable to update watch list: %v", err)
+			return fmt.Errorf("invalid service account key: %w", err)
+		}
+		if config.Email == "" || len(config.PrivateKey) == 0 {
+			return errors.New("invalid service account key: client_email or private_key missing")
 		}
-		currentParentID = folderID.Id
+		return nil
 	}
+	if t.GetSubject() != "" {
+		return errors.New("only service accounts can act as another user")
+	}
+	return json.Unmarshal([]byte(t.GetValue()), new(oauth2.Token))
 }
 
-func gDriveDeleteFolders(watchList *pb.WatchList) {
-	fmt.Println("Deleting Folders:")
-	err := gDriveService.Files.Delete(watchList.GetDriveId()).Context(context.Background()).Do()
+// gDriveUserTokenSource refreshes the stored OAuth token with the client it
+// was issued to.
+func gDriveUserTokenSource(ctx context.Context) (oauth2.TokenSource, error) {
+	b, err := gDriveOAuthClient()
 	if err != nil {
-		log.Printf("Failed to delete folder with ID %s, %s: %v", watchList.GetDriveId(), watchList.GetName(), err)
-	} else {
-		fmt.Printf("Successfully deleted folder with ID %s, %s\n", watchList.GetDriveId(), watchList.GetName())
+		return nil, err
+	}
+	config, err := google.ConfigFromJSON(b, drive.DriveScope)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse client secret file to config: %w", err)
 	}
-}
 
-func gDriveDeleteFiles(node *pb.Node) {
-	fmt.Println("Deleting Files:")
+	tok := &oauth2.Token{}
+	err = json.Unmarshal([]byte(tokenValue()), tok)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return nil, err
+	}
+	return &persistingTokenSource{base: config.TokenSource(ctx, tok), owner: token, last: tok}, nil
+}
 
-	err := gDriveService.Files.Delete(node.GetDriveId()).Context(context.Background()).Do()
+// gDriveServiceAccountTokenSource signs access tokens with the stored service
+// account key. With a subject, the service account acts as that user of its
+// Workspace domain.
+func gDriveServiceAccountTokenSource(ctx context.Context) (oauth2.TokenSource, error) {
+	config, err := google.JWTConfigFromJSON([]byte(tokenValue()), drive.DriveScope)
 	if err != nil {
-		log.Printf("Failed to delete file with ID %s, %s: %v", node.GetDriveId(), node.GetName(), err)
-	} else {
-		fmt.Printf("Successfully deleted file with ID %s, %s\n", node.GetDriveId(), node.GetName())
+		return nil, fmt.Errorf("unable to parse service account key: %w", err)
 	}
+	config.Subject = token.GetSubject()
+	return oauth2.ReuseTokenSource(nil, config.TokenSource(ctx)), nil
 }
 
-func gDriveDeleteFromDriveRecord(driveRecord *pb.DriveRecord) {
-	fmt.Println("Deleting Files:")
+// persistingTokenSource stores every token base hands out that differs from
+// the last one, so that refreshed access tokens survive a restart of the
+// daemon. Once owner, the token base was set up from, was replaced by a login
+// or emptied by a logout, refreshed tokens are no longer stored.
+type persistingTokenSource struct {
+	base  oauth2.TokenSource
+	owner *pb.OAuth2Token
+	mutex sync.Mutex
+	last  *oauth2.Token
+}
 
-	err := gDriveService.Files.Delete(driveRecord.GetDriveId()).Context(context.Background()).Do()
+func (s *persistingTokenSource) Token() (*oauth2.Token, error) {
+	tok, err := s.base.Token()
 	if err != nil {
-		log.Printf("Failed to delete file with ID %s, %s: %v", driveRecord.GetDriveId(), driveRecord.GetName(), err)
-	} else {
-		fmt.Printf("Successfully deleted file with ID %s, %s\n", driveRecord.GetDriveId(), driveRecord.GetName())
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.last != nil && s.last.AccessToken == tok.AccessToken && s.last.RefreshToken == tok.RefreshToken {
+		return tok, nil
+	}
+	s.last = tok
+
+	value, err := json.Marshal(tok)
+	if err != nil {
+		log.Printf("Unable to store the refreshed token: %v", err)
+		return tok, nil
 	}
+	tokenMutex.Lock()
+	defer tokenMutex.Unlock()
+	if token != s.owner || token.GetValue() == "" {
+		return tok, nil
+	}
+	token.Value = string(value)
+	err = writeToken()
+	if err != nil {
+		log.Printf("Unable to store the refreshed token: %v", err)
+	}
+	return tok, nil
 }
 
-func gDriveGetClient(config *oauth2.Config) (*http.Client, error) {
-	tok := &oauth2.Token{}
-	err := json.Unmarshal([]byte(token.GetValue()), tok)
+// gDriveEmail returns the email address of the connected account.
+func gDriveEmail() (string, error) {
+	about, err := gDriveService.About.Get().Fields("user(emailAddress)").Do()
+	if err != nil {
+		return "", err
+	}
+	return about.User.EmailAddress, nil
+}
+
+// gDriveScopes returns the scopes granted to an access token.
+func gDriveScopes(accessToken string) ([]string, error) {
+	res, err := http.PostForm(gDriveTokenInfoURL, url.Values{"access_token": {accessToken}})
 	if err != nil {
-		fmt.Println("Error:", err)
 		return nil, err
 	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token info: %s", res.Status)
+	}
 
-	return config.Client(context.Background(), tok), nil
+	var info struct {
+		Scope string `json:"scope"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&info)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(info.Scope), nil
+}
+
+// gDriveRevoke revokes the OAuth token value at Google. Revoking the refresh
+// token invalidates the access tokens issued with it as well.
+func gDriveRevoke(value string) error {
+	tok := new(oauth2.Token)
+	err := json.Unmarshal([]byte(value), tok)
+	if err != nil {
+		return err
+	}
+	revoked := tok.RefreshToken
+	if revoked == "" {
+		revoked = tok.AccessToken
+	}
+
+	res, err := http.PostForm(gDriveRevokeURL, url.Values{"token": {revoked}})
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("revoke: %s", res.Status)
+	}
+	return nil
 }
 
 func gDriveCreateFolder(name string, parents []string, localPath string) (*drive.File, error) {
 	var query string
 
 	if len(parents) > 0 {
-		query = fmt.Sprintf("name = '%s' and '%s' in parents and trashed = false and mimeType = 'application/vnd.google-apps.folder'", name, parents[0])
+		query = fmt.Sprintf("name = '%s' and '%s' in parents and trashed = false and mimeType = 'application/vnd.google-apps.folder'", gDriveQuote(name), parents[0])
 	} else {
-		query = fmt.Sprintf("name = '%s' and 'root' in parents and trashed = false and mimeType = 'application/vnd.google-apps.folder'", name)
+		query = fmt.Sprintf("name = '%s' and 'root' in parents and trashed = false and mimeType = 'application/vnd.google-apps.folder'", gDriveQuote(name))
 	}
 	r, err := gDriveService.Files.List().Q(query).Fields("files(id, name)").Do()
 	if err != nil {
@@ -292,75 +475,37 @@ func gDriveCreateFolder(name string, parents []string, localPath string) (*drive
 		Description: localPath,
 	}
 
-	folder, err = gDriveService.Files.Create(folder).Do()
-	if err != nil {
-		return nil, err
-	}
-	return folder, nil
+	return gDriveService.Files.Create(folder).Do()
 }
 
-func gDriveCreateFile(name string, parents []string, localPath string, fileContent io.Reader) (*drive.File, error) {
-	var query string
-
+func gDriveCreateFile(name string, parents []string, localPath string, f *pb.Node) (*drive.File, error) {
 	ext := filepath.Ext(name)
 	mimeType := mime.TypeByExtension(ext)
 	if mimeType == "" {
 		mimeType = "application/octet-stream" // Default to a generic binary stream
 	}
 
-	if len(parents) > 0 {
-		query = fmt.Sprintf("name = '%s' and '%s' in parents and trashed = false and mimeType = '%s'", name, parents[0], mimeType)
-	} else {
-		query = fmt.Sprintf("name = '%s' and 'root' in parents and trashed = false and mimeType = '%s'", name, mimeType)
-	}
-
-	r, err := gDriveService.Files.List().Q(query).Fields("files(id, name)").Do()
-	if err != nil {
-		return nil, err
-	}
-
-	if len(r.Files) > 0 {
-		return r.Files[0], nil
-	}
-
-	file := &drive.File{
+	metadata := &drive.File{
 		Name:        name,
 		MimeType:    mimeType,
 		Parents:     parents,
 		Description: localPath,
 	}
 
-	file, err = gDriveService.Files.Create(file).Media(fileContent).Do()
-	if err != nil {
-		return nil, err
-	}
-
-	fmt.Printf("File created: %s (%s)\n", file.Name, file.Id)
-	return file, nil
+	return gDriveResumableUpload(f, "", metadata)
 }
 
-func gDriveGetAllFolders() ([]*drive.File, error) {
-	query := fmt.Sprintf("mimeType = 'application/vnd.google-apps.folder' and '%s' in parents and trashed = false", token.GetHost())
-	files, err := gDriveService.Files.List().
-		Q(query).
-		Fields("files(id, name)").
-		Do()
-	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve folders: %v", err)
-	}
-
-	return files.Files, nil
+func gDriveUpdateFile(fileID string, f *pb.Node) (*drive.File, error) {
+	return gDriveResumableUpload(f, fileID, &drive.File{})
 }
 
-func gDriveGetAllFiles() ([]*drive.File, error) {
-	query := fmt.Sprintf("'%s' in parents and trashed = false and mimeType != 'application/vnd.google-apps.folder'", token.GetHost())
-	files, err := gDriveService.Files.List().
-		Q(query).
-		Fields("files(id, name)").
-		Do()
-	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve folders: %v", err)
+func gDriveMoveFile(fileID, name, localPath, oldParentID, newParentID string) (*drive.File, error) {
+	call := gDriveService.Files.Update(fileID, &drive.File{
+		Name:        name,
+		Description: localPath,
+	})
+	if oldParentID != newParentID {
+		call = call.AddParents(newParentID).RemoveParents(oldParentID)
 	}
-
-	return files.Files, nil
+	return call.Do()
 }
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"github.com/Regis-Caelum/drive-sync/config"
+	"github.com/Regis-Caelum/drive-sync/daemon/common"
+	"github.com/Regis-Caelum/drive-sync/daemon/database"
+	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ignoreFileName is the name of the files holding the exclusion rules of a
+	// directory and everything below it.
+	ignoreFileName = ".dsyncignore"
+)
+
+type ignoreFile struct {
+	modTime time.Time
+	size    int64
+	rules   []common.IgnoreRule
+}
+
+var (
+	ignoreMutex sync.Mutex
+	ignoreFiles = make(map[string]*ignoreFile)
+
+	// inlineIgnore caches the parsed ignore setting of a configuration.
+	inlineIgnore struct {
+		cfg   *config.Config
+		rules []common.IgnoreRule
+	}
+)
+
+// loadIgnoreFile returns the rules of the ignore file at path, or nil when
+// there is none. Files are parsed again when they changed.
+func loadIgnoreFile(path string) []common.IgnoreRule {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return nil
+	}
+
+	ignoreMutex.Lock()
+	defer ignoreMutex.Unlock()
+
+	cached, ok := ignoreFiles[path]
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.rules
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Println("Error:", err)
+		return nil
+	}
+	defer file.Close()
+
+	rules, err := common.ParseIgnore(file)
+	if err != nil {
+		log.Printf("Unable to read %s: %v", path, err)
+	}
+	ignoreFiles[path] = &ignoreFile{modTime: info.ModTime(), size: info.Size(), rules: rules}
+	return rules
+}
+
+// globalIgnoreRules returns the rules applied to every watched directory: those
+// of the configured ignore file followed by the inline ones.
+func globalIgnoreRules() []common.IgnoreRule {
+	cfg := config.Current()
+	rules := loadIgnoreFile(cfg.IgnoreFile)
+	if len(cfg.Ignore) == 0 {
+		return rules
+	}
+
+	ignoreMutex.Lock()
+	defer ignoreMutex.Unlock()
+
+	if inlineIgnore.cfg != cfg {
+		inlineIgnore.rules, _ = common.ParseIgnore(strings.NewReader(strings.Join(cfg.Ignore, "\n")))
+		inlineIgnore.cfg = cfg
+	}
+	return append(rules[:len(rules):len(rules)], inlineIgnore.rules...)
+}
+
+// watchRoot returns the top-level watched directory path belongs to. Paths
+// outside every watched directory are about to be added and are their own
+// root.
+func watchRoot(path string, isDir bool) string {
+	ancestors, err := database.GetWatchListAncestors(path)
+	if err == nil && len(ancestors) != 0 {
+		return ancestors[0].GetAbsolutePath()
+	}
+	if isDir {
+		return path
+	}
+	return filepath.Dir(path)
+}
+
+// isIgnored reports whether path is excluded by the hidden policy of its
+// watched directory, by the global ignore file or by a .dsyncignore file. Like
+// in git, everything below an excluded directory is excluded as well. Only the
+// part of the path below the watched directory is considered, so that watched
+// directories may lie inside hidden ones.
+func isIgnored(path string, isDir bool) bool {
+	if isTempName(filepath.Base(path)) {
+		return true
+	}
+
+	root := watchRoot(path, isDir)
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+
+	policy := pb.HIDDEN_POLICY_EXCLUDE_HIDDEN_DIRS
+	if w, err := database.GetWatchList(root); err == nil {
+		policy = w.GetHiddenPolicy()
+	}
+
+	current := root
+	parts := strings.Split(rel, string(filepath.Separator))
+	for i, part := range parts {
+		current = filepath.Join(current, part)
+		partIsDir := i < len(parts)-1 || isDir
+		if hiddenExcluded(policy, part, partIsDir) || ignoredIn(root, current, partIsDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// hiddenExcluded reports whether the hidden policy excludes the entry called
+// name.
+func hiddenExcluded(policy pb.HIDDEN_POLICY, name string, isDir bool) bool {
+	if !strings.HasPrefix(name, ".") {
+		return false
+	}
+	switch policy {
+	case pb.HIDDEN_POLICY_INCLUDE_HIDDEN:
+		return false
+	case pb.HIDDEN_POLICY_EXCLUDE_HIDDEN:
+		return true
+	}
+	return isDir
+}
+
+// ignoredIn applies the global rules and the rules of the .dsyncignore files
+// from root down to the parent of path.
+func ignoredIn(root, path string, isDir bool) bool {
+	rel, _ := filepath.Rel(root, path)
+	ignored := common.MatchIgnore(globalIgnoreRules(), filepath.ToSlash(rel), isDir, false)
+
+	dir := root
+	parts := strings.Split(filepath.Dir(rel), string(filepath.Separator))
+	for i := 0; ; i++ {
+		rel, _ = filepath.Rel(dir, path)
+		ignored = common.MatchIgnore(loadIgnoreFile(filepath.Join(dir, ignoreFileName)), filepath.ToSlash(rel), isDir,
+			ignored)
+		if i == len(parts) || parts[i] == "." {
+			return ignored
+		}
+		dir = filepath.Join(dir, parts[i])
+	}
+}
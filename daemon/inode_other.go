@@ -0,0 +1,12 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// fileInode returns the inode number of the file described by info. Inodes
+// are only read on Unix systems, elsewhere moves are recognized by size and
+// modification time alone.
+func fileInode(info os.FileInfo) uint64 {
+	return 0
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Regis-Caelum/drive-sync/config"
+	"log"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+var (
+	listenMutex sync.Mutex
+	// listeners holds the listener of the Unix socket and the one of the
+	// optional TCP address, keyed by network.
+	listeners = make(map[string]net.Listener)
+)
+
+// listenUnix serves gRPC on the Unix socket at path and stops serving on the
+// socket used before. The socket may be used by every local user, or only by
+// the members of group. Callers are authorized by their UID.
+func listenUnix(path, group string) error {
+	listenMutex.Lock()
+	current := listeners["unix"]
+	listenMutex.Unlock()
+	if current != nil && current.Addr().String() == path {
+		return setSocketMode(path, group)
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	// A socket is left behind when the daemon is killed. It is only removed
+	// when no other daemon answers on it.
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return fmt.Errorf("another daemon is listening on %s", path)
+	}
+	_ = os.Remove(path)
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	err = setSocketMode(path, group)
+	if err != nil {
+		l.Close()
+		return err
+	}
+	return serve("unix", l)
+}
+
+// setSocketMode lets every local user connect to the socket, or only the
+// members of group.
+func setSocketMode(path, group string) error {
+	if group == "" {
+		return os.Chmod(path, 0666)
+	}
+
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		return err
+	}
+	gid, err := strconv.Atoi(g.Gid)
+	if err != nil {
+		return err
+	}
+	err = os.Chown(path, -1, gid)
+	if err != nil {
+		return err
+	}
+	return os.Chmod(path, 0660)
+}
+
+// listenTCP serves gRPC on addr and stops serving on the TCP address used
+// before. An empty addr only stops serving on TCP.
+func listenTCP(addr string) error {
+	if addr == "" {
+		return serve("tcp", nil)
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	if config.Current().TLS.Cert == "" {
+		log.Printf("Serving on %s without TLS, callers there are not authenticated and can not access any files", addr)
+	}
+	return serve("tcp", l)
+}
+
+// serve replaces the listener of network by l.
+func serve(network string, l net.Listener) error {
+	listenMutex.Lock()
+	previous := listeners[network]
+	if l != nil {
+		listeners[network] = l
+	} else {
+		delete(listeners, network)
+	}
+	listenMutex.Unlock()
+
+	if l != nil {
+		fmt.Printf("Starting gRPC server on %s...\n", l.Addr())
+		go func() {
+			err := srv.Serve(l)
+			if err != nil && !errors.Is(err, net.ErrClosed) {
+				log.Fatalf("failed to serve: %v", err)
+			}
+		}()
+	}
+
+	if previous != nil {
+		return previous.Close()
+	}
+	return nil
+}
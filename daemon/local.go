@@ -0,0 +1,199 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Regis-Caelum/drive-sync/daemon/common"
+	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// localBackend mirrors the watched trees into a directory, for instance a
+// mounted network share or a second disk. Entries are addressed by their
+// slash separated path relative to the root directory.
+type localBackend struct {
+	root string
+}
+
+func newLocalBackend(root string) (Backend, error) {
+	if root == "" {
+		return nil, errors.New("root of [backend.local] is not set")
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(root, os.ModePerm)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create local backend root: %w", err)
+	}
+	return &localBackend{root: root}, nil
+}
+
+func (b *localBackend) Name() string {
+	return "local"
+}
+
+func (b *localBackend) EnsureFolder(name, parentID, localPath string) (string, error) {
+	id := path.Join(parentID, name)
+	err := os.MkdirAll(b.path(id), os.ModePerm)
+	if err != nil {
+		return "", b.wrap(err)
+	}
+	return id, nil
+}
+
+func (b *localBackend) CreateFile(name, parentID string, f *pb.Node) (*remoteEntry, error) {
+	return b.UpdateFile(path.Join(parentID, name), f)
+}
+
+func (b *localBackend) UpdateFile(id string, f *pb.Node) (*remoteEntry, error) {
+	err := b.copyFile(id, f)
+	if err != nil {
+		return nil, err
+	}
+	return b.Stat(id)
+}
+
+func (b *localBackend) Move(id, name, localPath, oldParentID, newParentID string) (string, error) {
+	newID := path.Join(newParentID, name)
+	err := os.Rename(b.path(id), b.path(newID))
+	if err != nil {
+		return "", b.wrap(err)
+	}
+	return newID, nil
+}
+
+func (b *localBackend) Delete(id string) error {
+	_, err := os.Lstat(b.path(id))
+	if err != nil {
+		return b.wrap(err)
+	}
+	return os.RemoveAll(b.path(id))
+}
+
+func (b *localBackend) Exists(id string) (bool, error) {
+	_, err := os.Lstat(b.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (b *localBackend) Stat(id string) (*remoteEntry, error) {
+	info, err := os.Stat(b.path(id))
+	if err != nil {
+		return nil, b.wrap(err)
+	}
+	return b.entry(id, info)
+}
+
+func (b *localBackend) Lookup(name, parentID string) (*remoteEntry, error) {
+	entry, err := b.Stat(path.Join(parentID, name))
+	if errors.Is(err, errRemoteNotFound) {
+		return nil, nil
+	}
+	return entry, err
+}
+
+func (b *localBackend) Download(id string, offset int64, w io.Writer) error {
+	file, err := os.Open(b.path(id))
+	if err != nil {
+		return b.wrap(err)
+	}
+	defer file.Close()
+
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, file)
+	return err
+}
+
+func (b *localBackend) List(id string) ([]*remoteEntry, error) {
+	dirEntries, err := os.ReadDir(b.path(id))
+	if err != nil {
+		return nil, b.wrap(err)
+	}
+
+	var entries []*remoteEntry
+	for _, e := range dirEntries {
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, &remoteEntry{
+			ID:           path.Join(id, e.Name()),
+			Name:         e.Name(),
+			IsDir:        e.IsDir(),
+			Size:         info.Size(),
+			ModifiedTime: info.ModTime(),
+		})
+	}
+	return entries, nil
+}
+
+// entry describes the entry id, the checksum of files is computed from their
+// content.
+func (b *localBackend) entry(id string, info fs.FileInfo) (*remoteEntry, error) {
+	entry := &remoteEntry{
+		ID:           id,
+		Name:         info.Name(),
+		IsDir:        info.IsDir(),
+		Size:         info.Size(),
+		ModifiedTime: info.ModTime(),
+	}
+	if !info.IsDir() {
+		sum, err := common.FileMD5(b.path(id))
+		if err != nil {
+			return nil, b.wrap(err)
+		}
+		entry.MD5 = sum
+	}
+	return entry, nil
+}
+
+func (b *localBackend) path(id string) string {
+	return filepath.Join(b.root, filepath.FromSlash(id))
+}
+
+// copyFile replaces the file id with the content of the node. The content is
+// written to a temporary file first, so the file is never left half written.
+func (b *localBackend) copyFile(id string, f *pb.Node) error {
+	src, err := os.Open(f.GetAbsolutePath())
+	if err != nil {
+		return fmt.Errorf("unable to open local file: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := os.CreateTemp(filepath.Dir(b.path(id)), ".dsync-*")
+	if err != nil {
+		return b.wrap(err)
+	}
+	defer os.Remove(dst.Name())
+
+	_, err = io.Copy(dst, throttleUpload(src))
+	if err == nil {
+		err = dst.Close()
+	} else {
+		dst.Close()
+	}
+	if err != nil {
+		return err
+	}
+	return b.wrap(os.Rename(dst.Name(), b.path(id)))
+}
+
+// wrap marks errors about missing entries so that the recorded remote state
+// gets repaired.
+func (b *localBackend) wrap(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %w", errRemoteNotFound, err)
+	}
+	return err
+}
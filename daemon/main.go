@@ -3,11 +3,13 @@ package main
 import (
 	"context"
 	"fmt"
+	"github.com/Regis-Caelum/drive-sync/config"
 	"github.com/Regis-Caelum/drive-sync/daemon/database"
 	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"log"
-	"net"
 	"os"
 )
 
@@ -19,24 +21,86 @@ type server struct {
 }
 
 func (s *server) SaveToken(ctx context.Context, in *pb.OAuth2Token) (*pb.Empty, error) {
-	tx, err := database.GetTx()
-	log.Println("Transaction started")
+	err := callerFrom(ctx).requireAdmin("log in")
 	if err != nil {
-		fmt.Println("Error:", err)
-		return nil, fmt.Errorf("unabe to connect to database")
+		return nil, err
 	}
-	defer database.RollbackTx(tx)
 
+	if in.GetClient() == "" {
+		in.Client = token.GetClient()
+	}
+	err = checkCredentials(in)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Wait for running jobs and keep new ones from starting until relogin
+	// set the backend up with the new token.
+	remoteMutex.Lock()
+	defer remoteMutex.Unlock()
+
+	// The remote state stays until initBackend sees another account.
 	in.Id = 1
-	tx.Save(in)
-	database.CommitTx(tx)
-	token = in
-	log.Println("Transaction Ended")
+	in.Backend = token.GetBackend()
+	in.Account = token.GetAccount()
+	in.Root = token.GetRoot()
+	in.Host = token.GetHost()
+	in.ChangesToken = token.GetChangesToken()
+	previous, previousToken, previousClient := token, unreadableToken, unreadableClient
+	err = updateToken(func() {
+		token = in
+		unreadableToken = ""
+		unreadableClient = ""
+	})
+	if err != nil {
+		tokenMutex.Lock()
+		token, unreadableToken, unreadableClient = previous, previousToken, previousClient
+		tokenMutex.Unlock()
+		return nil, fmt.Errorf("unable to store the token: %v", err)
+	}
+	remoteAuthRequired.Store(true)
+	// Setting up the backend may wait for the remote, the caller need not.
+	go relogin()
 	return &pb.Empty{}, nil
 }
 
-func (s *server) GetToken(ctx context.Context, in *pb.Empty) (*pb.OAuth2Token, error) {
-	return token, nil
+func (s *server) GetAuthStatus(ctx context.Context, in *pb.Empty) (*pb.AuthStatus, error) {
+	c := callerFrom(ctx)
+	if !c.local && !c.certified {
+		return nil, status.Errorf(codes.PermissionDenied, "%s is not allowed to read the login status", c)
+	}
+	remoteMutex.RLock()
+	defer remoteMutex.RUnlock()
+	return authStatus(), nil
+}
+
+func (s *server) GetOAuthClient(ctx context.Context, in *pb.Empty) (*pb.OAuthClient, error) {
+	err := callerFrom(ctx).requireAdmin("log in")
+	if err != nil {
+		return nil, err
+	}
+	b, err := gDriveOAuthClient()
+	if err != nil {
+		return nil, err
+	}
+	return &pb.OAuthClient{Config: string(b)}, nil
+}
+
+func (s *server) Logout(ctx context.Context, in *pb.LogoutRequest) (*pb.Empty, error) {
+	err := callerFrom(ctx).requireAdmin("log out")
+	if err != nil {
+		return nil, err
+	}
+	if name := config.Current().Backend.Name; name != "drive" {
+		return nil, status.Errorf(codes.FailedPrecondition, "the %s backend has no login", name)
+	}
+	remoteMutex.Lock()
+	err = logout(in.GetMode())
+	remoteMutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Empty{}, nil
 }
 
 func (s *server) GetWatchList(ctx context.Context, in *pb.Empty) (*pb.FileList, error) {
@@ -54,16 +118,35 @@ func (s *server) GetWatchList(ctx context.Context, in *pb.Empty) (*pb.FileList,
 	tx.Find(&watchList)
 	tx.Find(&nodeList)
 
-	resp.DirectoryList = watchList
-	resp.FileList = nodeList
+	c := callerFrom(ctx)
+	for _, w := range watchList {
+		if c.sees(w.GetAbsolutePath()) {
+			resp.DirectoryList = append(resp.DirectoryList, w)
+		}
+	}
+	for _, n := range nodeList {
+		if c.sees(n.GetAbsolutePath()) {
+			resp.FileList = append(resp.FileList, n)
+		}
+	}
 	return resp, nil
 }
 
 func (s *server) AddDirectoriesToWatchList(ctx context.Context, in *pb.PathList) (*pb.ResponseList, error) {
+	c := callerFrom(ctx)
 	resp := new(pb.ResponseList)
 	for _, path := range in.GetValues() {
+		if err := c.checkPaths(path); err != nil {
+			fmt.Printf("Adding path %s to watchlist...	❌\n", path)
+			resp.Values = append(resp.Values, &pb.AddDirectoryResponse{
+				Status: pb.ADD_DIRECTORY_STATUS_FAILED,
+				Error:  status.Convert(err).Message(),
+				Path:   path,
+			})
+			continue
+		}
 		if _, err := os.Stat(path); !os.IsNotExist(err) {
-			err = traverseDirHelper(path)
+			err = addWatchRoot(path, in.HiddenPolicy)
 			if err != nil {
 				fmt.Printf("Adding path %s to watchlist...	✔❌\n", path)
 				resp.Values = append(resp.Values, &pb.AddDirectoryResponse{
@@ -91,8 +174,115 @@ func (s *server) AddDirectoriesToWatchList(ctx context.Context, in *pb.PathList)
 	return resp, nil
 }
 
+func (s *server) GetConflicts(ctx context.Context, in *pb.Empty) (*pb.FileList, error) {
+	nodes, err := database.GetNodesByUploadStatus(pb.FILE_STATUS_CONFLICT)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return nil, fmt.Errorf("unable to list conflicts")
+	}
+
+	c := callerFrom(ctx)
+	resp := new(pb.FileList)
+	for _, n := range nodes {
+		if c.sees(n.GetAbsolutePath()) {
+			resp.FileList = append(resp.FileList, n)
+		}
+	}
+	return resp, nil
+}
+
+func (s *server) ResolveConflict(ctx context.Context, in *pb.ConflictResolution) (*pb.Empty, error) {
+	if in.GetPolicy() == pb.CONFLICT_POLICY_MANUAL {
+		return nil, fmt.Errorf("a conflict can not be resolved manually again")
+	}
+	err := callerFrom(ctx).checkPaths(in.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	node, err := database.GetNodeByAbsolutePath(in.GetPath())
+	if err != nil || node.GetUploadStatus() != pb.FILE_STATUS_CONFLICT {
+		return nil, fmt.Errorf("%s has no conflict", in.GetPath())
+	}
+
+	enqueueJob(&pb.Job{Action: pb.JOB_ACTION_RESOLVE_CONFLICT, Path: in.GetPath(), Policy: in.GetPolicy()})
+	return &pb.Empty{}, nil
+}
+
+func (s *server) Pull(in *pb.PullRequest, stream pb.WatchListService_PullServer) error {
+	_, localPath, err := pullLocalPath(in.GetPath())
+	if err != nil {
+		return err
+	}
+	paths := []string{localPath}
+	if in.GetTo() != "" {
+		paths = append(paths, in.GetTo())
+	}
+	err = callerFrom(stream.Context()).checkPaths(paths...)
+	if err != nil {
+		return err
+	}
+	return pull(in, stream.Send)
+}
+
+func (s *server) ListHosts(ctx context.Context, in *pb.Empty) (*pb.HostList, error) {
+	c := callerFrom(ctx)
+	if !c.local && !c.certified {
+		return nil, status.Errorf(codes.PermissionDenied, "%s is not allowed to list the hosts", c)
+	}
+	remoteMutex.RLock()
+	defer remoteMutex.RUnlock()
+	if !remoteReady() {
+		return nil, fmt.Errorf("no backend connected")
+	}
+	hosts, err := listHosts()
+	if err != nil {
+		fmt.Println("Error:", err)
+		return nil, err
+	}
+
+	hostname, _ := os.Hostname()
+	resp := new(pb.HostList)
+	for _, h := range hosts {
+		resp.Hosts = append(resp.Hosts, &pb.Host{Name: h.Name, Current: h.Name == hostname})
+	}
+	return resp, nil
+}
+
+func (s *server) GetRevisions(ctx context.Context, in *pb.RevisionRequest) (*pb.RevisionList, error) {
+	remoteMutex.RLock()
+	defer remoteMutex.RUnlock()
+	if !remoteReady() {
+		return nil, fmt.Errorf("no backend connected")
+	}
+	_, localPath, err := pullLocalPath(in.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	err = callerFrom(ctx).checkPaths(localPath)
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := listRevisions(in.GetPath())
+	if err != nil {
+		fmt.Println("Error:", err)
+		return nil, err
+	}
+
+	resp := &pb.RevisionList{Path: in.GetPath()}
+	for _, r := range revisions {
+		resp.Revisions = append(resp.Revisions, &pb.Revision{
+			Id:           r.ID,
+			ModifiedTime: r.ModifiedTime.Unix(),
+			Size:         r.Size,
+			Md5:          r.MD5,
+			KeepForever:  r.KeepForever,
+		})
+	}
+	return resp, nil
+}
+
 func init() {
-	srv = grpc.NewServer()
+	srv = grpc.NewServer(grpc.Creds(peerCredentials{}))
 	pb.RegisterWatchListServiceServer(srv, &server{})
 	pb.RegisterAuthenticationServiceServer(srv, &server{})
 }
@@ -103,13 +293,20 @@ func main() {
 	<-daemonChannel
 	fmt.Println("Watchlist daemon up and running.")
 
-	listen, err := net.Listen("tcp", ":58295")
+	cfg := config.Current()
+	_, err := serverTLS(cfg)
+	if err != nil {
+		log.Fatal("invalid configuration: ", err)
+	}
+	err = listenUnix(cfg.Listen, cfg.Access.SocketGroup)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
-
-	fmt.Println("Starting gRPC server on Unix socket...")
-	if err = srv.Serve(listen); err != nil {
-		log.Fatalf("failed to serve: %v", err)
+	if cfg.ListenTCP != "" {
+		err = listenTCP(cfg.ListenTCP)
+		if err != nil {
+			log.Fatalf("failed to listen: %v", err)
+		}
 	}
+	reloadOnHangup()
 }
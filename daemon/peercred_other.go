@@ -0,0 +1,14 @@
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+	"net"
+)
+
+// peerUID returns the UID of the process on the other end of a Unix socket.
+// Peer credentials are only read on Linux.
+func peerUID(conn *net.UnixConn) (uint32, error) {
+	return 0, errors.New("peer credentials are not supported on this system")
+}
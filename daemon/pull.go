@@ -0,0 +1,468 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Regis-Caelum/drive-sync/daemon/common"
+	"github.com/Regis-Caelum/drive-sync/daemon/database"
+	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// pullProgressInterval is how often the progress of a running download is
+// reported.
+const pullProgressInterval = 500 * time.Millisecond
+
+// errPullCanceled stops a pull whose client went away. The part file of the
+// running download is kept and resumed by the next pull.
+var errPullCanceled = errors.New("pull canceled")
+
+// puller downloads a remote subtree for `dsync pull`. Files restored to the
+// path they were uploaded from are recorded as synced, everything else is
+// left to the watcher like any other new file. With at set, every file is
+// downloaded in the revision that was current at that time. The subtree is
+// downloaded from remote, the backend it was found on.
+type puller struct {
+	remote Backend
+	dryRun bool
+	at     time.Time
+	send   func(*pb.PullProgress) error
+}
+
+// pull resolves the local or remote path of the request and downloads the
+// subtree below it, reporting every file through send. Paths of other hosts
+// are looked up in their host folder. Only the lookup holds remoteMutex, a
+// login does not wait for the downloads or for a client that stopped reading.
+func pull(in *pb.PullRequest, send func(*pb.PullProgress) error) error {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("unable to get hostname: %w", err)
+	}
+
+	host, localPath, err := pullLocalPath(in.GetPath())
+	if err != nil {
+		return err
+	}
+	if in.GetHost() != "" {
+		if host != "" && host != in.GetHost() {
+			return fmt.Errorf("%s belongs to host %s, not %s", in.GetPath(), host, in.GetHost())
+		}
+		host = in.GetHost()
+	}
+	if host == "" {
+		host = hostname
+	}
+
+	remoteMutex.RLock()
+	remote := backend
+	entry, parentID, err := findPullEntry(host, hostname, localPath)
+	remoteMutex.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	target := localPath
+	if in.GetTo() != "" {
+		target = filepath.Join(in.GetTo(), filepath.Base(localPath))
+	}
+
+	p := &puller{remote: remote, dryRun: in.GetDryRun(), send: send}
+	if in.GetAt() != 0 {
+		if _, ok := remote.(revisionBackend); !ok {
+			return fmt.Errorf("%w: %s", errNoRevisions, remote.Name())
+		}
+		p.at = time.Unix(in.GetAt(), 0)
+	}
+	return p.pullEntry(entry, parentID, target, host == hostname && target == localPath)
+}
+
+// findPullEntry returns the remote entry mirroring localPath on host together
+// with the ID of its parent folder. The caller holds remoteMutex.
+func findPullEntry(host, hostname, localPath string) (*remoteEntry, string, error) {
+	if !remoteReady() {
+		return nil, "", errors.New("no backend connected")
+	}
+	if host == hostname {
+		return findRemotePath(localPath)
+	}
+	hostEntry, err := findHost(host)
+	if err != nil {
+		return nil, "", err
+	}
+	return lookupRemotePath(hostEntry, localPath)
+}
+
+// pullLocalPath maps the path given to `dsync pull` to the local path it was
+// uploaded from. Remote paths are given relative to the top of the backend,
+// like Computers/<host>/home/me/docs, the host is returned for those.
+func pullLocalPath(path string) (string, string, error) {
+	if filepath.IsAbs(path) {
+		return "", filepath.Clean(path), nil
+	}
+
+	parts := strings.SplitN(filepath.ToSlash(filepath.Clean(path)), "/", 3)
+	if len(parts) < 2 || parts[0] != "Computers" {
+		return "", "", fmt.Errorf("%s is neither an absolute local path nor a Computers/<host>/... path", path)
+	}
+	if len(parts) == 2 {
+		return parts[1], "/", nil
+	}
+	return parts[1], "/" + parts[2], nil
+}
+
+// listHosts returns the host folders below the Computers folder, one for
+// every machine that uploaded to the backend.
+func listHosts() ([]*remoteEntry, error) {
+	var entries []*remoteEntry
+	err := retryRemote("List hosts", func() (err error) {
+		entries, err = backend.List(rootFolder())
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list hosts: %w", err)
+	}
+
+	var hosts []*remoteEntry
+	for _, e := range entries {
+		if e.IsDir {
+			hosts = append(hosts, e)
+		}
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Name < hosts[j].Name })
+	return hosts, nil
+}
+
+// findHost returns the host folder of the machine called name.
+func findHost(name string) (*remoteEntry, error) {
+	hosts, err := listHosts()
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range hosts {
+		if h.Name == name {
+			return h, nil
+		}
+	}
+	return nil, fmt.Errorf("there is no folder of host %s on %s", name, backend.Name())
+}
+
+// findRemotePath returns the remote entry mirroring localPath together with
+// the ID of its parent folder. It uses the recorded mapping where there is one
+// and looks the path up below the host folder otherwise, for instance after
+// the local database was lost.
+func findRemotePath(localPath string) (*remoteEntry, string, error) {
+	if rec, err := database.GetDriveRecordByLocalPath(localPath); err == nil {
+		var entry *remoteEntry
+		err = retryRemote("Check "+localPath, func() (err error) {
+			entry, err = backend.Stat(rec.GetDriveId())
+			return err
+		})
+		if err == nil {
+			return entry, rec.GetParentId(), nil
+		}
+		if !errors.Is(err, errRemoteNotFound) {
+			return nil, "", err
+		}
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to get hostname: %w", err)
+	}
+	return lookupRemotePath(&remoteEntry{ID: hostFolder(), Name: hostname, IsDir: true}, localPath)
+}
+
+// lookupRemotePath walks from the host folder down to the entry mirroring
+// localPath, folder by folder. It returns the entry and the ID of its parent.
+func lookupRemotePath(host *remoteEntry, localPath string) (*remoteEntry, string, error) {
+	entry := host
+	parentID := rootFolder()
+	for _, name := range strings.Split(localPath, string(filepath.Separator)) {
+		if name == "" {
+			continue
+		}
+		var entries []*remoteEntry
+		err := retryRemote("List "+entry.Name, func() (err error) {
+			entries, err = backend.List(entry.ID)
+			return err
+		})
+		if err != nil {
+			return nil, "", err
+		}
+
+		parentID = entry.ID
+		entry = nil
+		for _, e := range entries {
+			if e.Name == name {
+				entry = e
+				break
+			}
+		}
+		if entry == nil {
+			return nil, "", fmt.Errorf("%s was not found in the folder of host %s on %s", localPath, host.Name,
+				backend.Name())
+		}
+	}
+	return entry, parentID, nil
+}
+
+// pullEntry downloads the entry to target. inPlace tells whether target is
+// the path the entry was uploaded from.
+func (p *puller) pullEntry(entry *remoteEntry, parentID, target string, inPlace bool) error {
+	if !entry.IsDir {
+		return p.pullFile(entry, parentID, target, inPlace)
+	}
+
+	if !p.dryRun {
+		err := p.createFolder(entry, parentID, target, inPlace)
+		if err != nil {
+			return err
+		}
+	}
+
+	var entries []*remoteEntry
+	err := retryRemote("List "+target, func() (err error) {
+		entries, err = p.remote.List(entry.ID)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list %s: %w", target, err)
+	}
+	for _, e := range entries {
+		if isTempName(e.Name) {
+			continue
+		}
+		err = p.pullEntry(e, entry.ID, filepath.Join(target, e.Name), inPlace)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createFolder creates the folder target. Folders restored in place inside a
+// watched directory are watched again.
+func (p *puller) createFolder(entry *remoteEntry, parentID, target string, inPlace bool) error {
+	if _, err := database.GetWatchList(target); err != nil && inPlace && !common.PathExist(target) && underWatch(target) {
+		return createLocalFolder(target, entry.ID, parentID)
+	}
+	err := os.MkdirAll(target, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("unable to create %s: %w", target, err)
+	}
+	return nil
+}
+
+// pullFile downloads a single file and reports the outcome. Failed downloads
+// are reported and do not stop the pull, only a client that went away does.
+func (p *puller) pullFile(entry *remoteEntry, parentID, target string, inPlace bool) error {
+	progress := &pb.PullProgress{Path: target, Size: entry.Size}
+	report := func(status pb.PULL_STATUS, err error) error {
+		progress.Status = status
+		progress.Error = ""
+		if err != nil {
+			progress.Error = err.Error()
+			log.Printf("Unable to pull %s: %v", target, err)
+		}
+		return p.send(progress)
+	}
+
+	revisionID := ""
+	if !p.at.IsZero() {
+		revision, err := revisionAt(p.remote, entry.ID, p.at)
+		if err != nil {
+			return report(pb.PULL_STATUS_DOWNLOAD_FAILED, err)
+		}
+		if revision == nil {
+			return report(pb.PULL_STATUS_DOWNLOAD_SKIPPED, errors.New("did not exist at that time"))
+		}
+		version := *entry
+		version.Size = revision.Size
+		version.MD5 = revision.MD5
+		version.ModifiedTime = revision.ModifiedTime
+		entry = &version
+		revisionID = revision.ID
+		progress.Size = entry.Size
+	}
+
+	if localUpToDate(target, entry) {
+		progress.Offset = entry.Size
+		return report(pb.PULL_STATUS_UP_TO_DATE, nil)
+	}
+	if node, err := database.GetNodeByAbsolutePath(target); err == nil && inPlace &&
+		(node.GetFileStatus() == pb.FILE_STATUS_MODIFIED || node.GetUploadStatus() == pb.FILE_STATUS_CONFLICT) {
+		return report(pb.PULL_STATUS_DOWNLOAD_SKIPPED, errors.New("changed locally and not uploaded yet"))
+	}
+	if p.dryRun {
+		return report(pb.PULL_STATUS_WOULD_DOWNLOAD, nil)
+	}
+
+	err := os.MkdirAll(filepath.Dir(target), os.ModePerm)
+	if err == nil {
+		err = p.download(entry, revisionID, target, progress)
+	}
+	if errors.Is(err, errPullCanceled) {
+		return err
+	}
+	if err != nil {
+		return report(pb.PULL_STATUS_DOWNLOAD_FAILED, err)
+	}
+	if inPlace && underWatch(target) {
+		if revisionID != "" {
+			// The earlier content becomes the current one and is uploaded
+			// as a new revision.
+			handleWrite(target)
+		} else if err = recordPulledFile(target, entry, parentID); err != nil {
+			log.Println("Error:", err)
+		}
+	}
+	fmt.Printf("File pulled: %s (%s)\n", target, entry.ID)
+	return report(pb.PULL_STATUS_DOWNLOADED, nil)
+}
+
+// download fetches the content of the entry, or of its revision revisionID,
+// into a hidden part file next to target and moves it into place once
+// complete. A part file left by an interrupted pull is resumed, or only
+// checked when it is complete already. The part file is kept from the
+// watcher while it is written.
+func (p *puller) download(entry *remoteEntry, revisionID, target string, progress *pb.PullProgress) error {
+	part := filepath.Join(filepath.Dir(target), "."+filepath.Base(target)+".dsync-part")
+
+	err := retryRemote("Download "+target, func() error {
+		var offset int64
+		if info, err := os.Stat(part); err == nil && info.Size() <= entry.Size {
+			offset = info.Size()
+		}
+		if offset > 0 && offset == entry.Size {
+			return nil
+		}
+		flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		if offset == 0 {
+			flags |= os.O_TRUNC
+		}
+		dst, err := os.OpenFile(part, flags, 0644)
+		if err != nil {
+			return err
+		}
+
+		progress.Offset = offset
+		suppressEcho(part)
+		w := throttleDownload(&progressWriter{w: dst, path: part, progress: progress, send: p.send})
+		if revisionID != "" {
+			err = p.remote.(revisionBackend).DownloadRevision(entry.ID, revisionID, offset, w)
+		} else {
+			err = p.remote.Download(entry.ID, offset, w)
+		}
+		if err == nil {
+			err = dst.Close()
+		} else {
+			dst.Close()
+		}
+		if errors.Is(classifyError(err), errRangeNotSatisfiable) && offset > 0 {
+			// The remote content shrank, start over.
+			if truncErr := os.Truncate(part, 0); truncErr != nil {
+				return truncErr
+			}
+			return fmt.Errorf("%w: %w", errRetryable, err)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if entry.MD5 != "" {
+		sum, err := common.FileMD5(part)
+		if err != nil {
+			return err
+		}
+		if sum != entry.MD5 {
+			// The remote file changed since the part was started.
+			os.Remove(part)
+			return errors.New("checksum mismatch, pull again to download it from the start")
+		}
+	}
+
+	suppressEcho(target)
+	err = os.Rename(part, target)
+	suppressEcho(target)
+	if err != nil {
+		return err
+	}
+	if !entry.ModifiedTime.IsZero() {
+		_ = os.Chtimes(target, entry.ModifiedTime, entry.ModifiedTime)
+	}
+	return nil
+}
+
+// localUpToDate reports whether the file at path has the content of the
+// remote entry already.
+func localUpToDate(path string, entry *remoteEntry) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() || info.Size() != entry.Size {
+		return false
+	}
+	if entry.MD5 == "" {
+		return true
+	}
+	sum, err := common.FileMD5(path)
+	return err == nil && sum == entry.MD5
+}
+
+// underWatch reports whether path lies inside a watched directory.
+func underWatch(path string) bool {
+	if _, err := database.GetWatchList(path); err == nil {
+		return true
+	}
+	ancestors, err := database.GetWatchListAncestors(path)
+	return err == nil && len(ancestors) != 0
+}
+
+// recordPulledFile records a file restored in place as synced, so that it is
+// not uploaded again.
+func recordPulledFile(path string, entry *remoteEntry, parentID string) error {
+	if node, err := database.GetNodeByAbsolutePath(path); err == nil {
+		err = database.UpdateNodeFields(node.GetId(), map[string]interface{}{
+			"file_status":          pb.FILE_STATUS_UNMODIFIED,
+			"upload_status":        pb.FILE_STATUS_UPLOADED,
+			"drive_id":             entry.ID,
+			"remote_md5":           entry.MD5,
+			"remote_modified_time": formatRemoteTime(entry.ModifiedTime),
+			"error":                "",
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return trackRemoteFile(path, entry, parentID)
+}
+
+// progressWriter writes a download to the part file at path and reports the
+// bytes written every pullProgressInterval.
+type progressWriter struct {
+	w        io.Writer
+	path     string
+	progress *pb.PullProgress
+	send     func(*pb.PullProgress) error
+	last     time.Time
+}
+
+func (w *progressWriter) Write(b []byte) (int, error) {
+	suppressEcho(w.path)
+	n, err := w.w.Write(b)
+	w.progress.Offset += int64(n)
+	if err == nil && time.Since(w.last) >= pullProgressInterval {
+		w.last = time.Now()
+		w.progress.Status = pb.PULL_STATUS_DOWNLOADING
+		if w.send(w.progress) != nil {
+			err = errPullCanceled
+		}
+	}
+	return n, err
+}
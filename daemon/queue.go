@@ -0,0 +1,205 @@
+package main
+
+import (
+	"errors"
+	"github.com/Regis-Caelum/drive-sync/daemon/database"
+	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
+	"gorm.io/gorm"
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// jobRetryDelay is how long a failed job waits before it is tried again.
+	// The delay doubles with every failed attempt up to jobMaxRetryDelay.
+	jobRetryDelay    = time.Minute
+	jobMaxRetryDelay = time.Hour
+
+	// jobAttempts is how often a job failing with a temporary error is run
+	// before it is given up and the failure is recorded on the node.
+	jobAttempts = 10
+
+	// jobPollInterval is how often idle workers look for jobs that became due.
+	jobPollInterval = 10 * time.Second
+)
+
+var queueSignal = make(chan struct{}, 1)
+
+var (
+	workerMutex sync.Mutex
+	// workerCount is the number of running workers, workerLimit the number
+	// there should be. Surplus workers stop once they are idle.
+	workerCount int
+	workerLimit int
+)
+
+// enqueueJob stores a job in the persistent queue and wakes up a worker.
+func enqueueJob(job *pb.Job) {
+	created, err := database.EnqueueJob(job)
+	if err != nil {
+		log.Println("Error:", err)
+		return
+	}
+	if created {
+		notifyWorkers()
+	}
+}
+
+func notifyWorkers() {
+	select {
+	case queueSignal <- struct{}{}:
+	default:
+	}
+}
+
+// setWorkerCount starts or stops workers until count of them are running.
+func setWorkerCount(count int) {
+	workerMutex.Lock()
+	defer workerMutex.Unlock()
+
+	workerLimit = count
+	for ; workerCount < workerLimit; workerCount++ {
+		go worker()
+	}
+}
+
+// stopSurplusWorker reports whether the calling worker should stop because
+// there are more workers than configured.
+func stopSurplusWorker() bool {
+	workerMutex.Lock()
+	defer workerMutex.Unlock()
+
+	if workerCount > workerLimit {
+		workerCount--
+		return true
+	}
+	return false
+}
+
+func worker() {
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if stopSurplusWorker() {
+			return
+		}
+		var job *pb.Job
+		var err error
+		remoteMutex.RLock()
+		if remoteReady() {
+			job, err = database.ClaimJob()
+			if err != nil {
+				log.Println("Error:", err)
+			}
+		}
+		if job == nil {
+			remoteMutex.RUnlock()
+			select {
+			case <-queueSignal:
+			case <-ticker.C:
+			}
+			continue
+		}
+
+		// There may be more work, let another idle worker have a look.
+		notifyWorkers()
+
+		err = finishJob(job, runJob(job))
+		remoteMutex.RUnlock()
+		if err != nil {
+			log.Println("Error:", err)
+		}
+	}
+}
+
+// finishJob removes a job from the queue or schedules it again, depending on
+// how it failed.
+func finishJob(job *pb.Job, cause error) error {
+	if cause == nil {
+		return database.CompleteJob(job.GetId())
+	}
+	log.Printf("Job %d (%s %s) failed: %v", job.GetId(), job.GetAction(), job.GetPath(), cause)
+
+	switch {
+	case errors.Is(cause, errUnauthorized):
+		// Keep the job as it is until someone logs in again.
+		requireLogin(cause)
+		job.Attempts--
+		return database.RetryJob(job, cause, 0)
+
+	case errors.Is(cause, errRemoteNotFound):
+		repairRemote(job.GetPath())
+		return database.CompleteJob(job.GetId())
+
+	case errors.Is(cause, errRetryable) && job.GetAttempts()+1 < jobAttempts:
+		recordJobFailure(job, cause, false)
+		delay := jobRetryDelay << job.GetAttempts()
+		if delay > jobMaxRetryDelay || delay <= 0 {
+			delay = jobMaxRetryDelay
+		}
+		return database.RetryJob(job, cause, delay)
+	}
+
+	recordJobFailure(job, cause, true)
+	return database.CompleteJob(job.GetId())
+}
+
+// recordJobFailure stores the error of a failed file job on its node. A final
+// failure marks the upload as failed; the file is tried again when it changes
+// or the daemon restarts.
+func recordJobFailure(job *pb.Job, cause error, final bool) {
+	if job.GetAction() != pb.JOB_ACTION_SYNC_FILE {
+		return
+	}
+	node, err := database.GetNodeByAbsolutePath(job.GetPath())
+	if err != nil {
+		return
+	}
+
+	fields := map[string]interface{}{"error": cause.Error()}
+	if final {
+		fields["upload_status"] = pb.FILE_STATUS_UPLOAD_FAILED
+	}
+	err = database.UpdateNodeFields(node.GetId(), fields)
+	if err != nil {
+		log.Println("Error:", err)
+	}
+}
+
+func runJob(job *pb.Job) error {
+	switch job.GetAction() {
+	case pb.JOB_ACTION_SYNC_FILE:
+		node, err := database.GetNodeByAbsolutePath(job.GetPath())
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// The file stopped being tracked before it was synced.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return syncFile(node)
+
+	case pb.JOB_ACTION_SYNC_FOLDER:
+		watchList, err := database.GetWatchList(job.GetPath())
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return syncFolder(watchList)
+
+	case pb.JOB_ACTION_DELETE_REMOTE:
+		return deleteRemote(job.GetDriveId(), filepath.Base(job.GetPath()))
+
+	case pb.JOB_ACTION_MOVE_REMOTE:
+		return syncMove(job)
+
+	case pb.JOB_ACTION_RESOLVE_CONFLICT:
+		return resolveHeldConflict(job)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"github.com/Regis-Caelum/drive-sync/config"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// reloadOnHangup reloads the configuration whenever the daemon receives
+// SIGHUP. It never returns.
+func reloadOnHangup() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	for range signals {
+		reloadConfig()
+	}
+}
+
+// reloadConfig reads the configuration again and applies what changed. The
+// database and the backend are only set up at startup, jobs may be running
+// against the backend at any time. Everything else takes effect at once. An
+// invalid configuration is rejected as a whole.
+func reloadConfig() {
+	previous := config.Current()
+	cfg, err := config.Load()
+	if err == nil {
+		_, err = serverTLS(cfg)
+	}
+	if err != nil {
+		log.Println("Unable to reload the configuration:", err)
+		return
+	}
+
+	if cfg.Database != previous.Database {
+		log.Println("The database path takes effect after a restart")
+		cfg.Database = previous.Database
+	}
+	if cfg.Backend != previous.Backend {
+		log.Println("The backend settings take effect after a restart")
+		cfg.Backend = previous.Backend
+	}
+	if cfg.Listen != previous.Listen || cfg.Access.SocketGroup != previous.Access.SocketGroup {
+		err = listenUnix(cfg.Listen, cfg.Access.SocketGroup)
+		if err != nil {
+			log.Println("Error:", err)
+			cfg.Listen = previous.Listen
+			cfg.Access.SocketGroup = previous.Access.SocketGroup
+		}
+	}
+	if cfg.ListenTCP != previous.ListenTCP {
+		err = listenTCP(cfg.ListenTCP)
+		if err != nil {
+			log.Println("Error:", err)
+			cfg.ListenTCP = previous.ListenTCP
+		}
+	}
+	config.Set(cfg)
+
+	setWorkerCount(cfg.UploadWorkers)
+	setBandwidth(cfg.Bandwidth)
+	log.Println("Configuration reloaded")
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/minio/minio-go/v7"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/googleapi"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	// retryAttempts is how often a backend call is tried before the error is
+	// handed back to the caller.
+	retryAttempts = 5
+
+	retryBaseDelay = time.Second
+	retryMaxDelay  = 32 * time.Second
+)
+
+var (
+	// errRetryable marks errors that are likely to go away on their own, like
+	// rate limiting, server errors and network failures.
+	errRetryable = errors.New("temporary remote error")
+
+	// errRemoteNotFound marks errors caused by a remote entry that no longer
+	// exists, which means the recorded remote state has to be repaired.
+	errRemoteNotFound = errors.New("remote entry not found")
+
+	// errUnauthorized marks errors caused by rejected credentials, which can
+	// only be fixed by logging in again.
+	errUnauthorized = errors.New("remote authorization failed")
+
+	// errRangeNotSatisfiable marks a download resumed past the end of the
+	// remote content, which changed since the download was started.
+	errRangeNotSatisfiable = errors.New("requested range not satisfiable")
+)
+
+// classifyError wraps err with the sentinel describing how it has to be
+// handled. Errors that match none of them are permanent.
+func classifyError(err error) error {
+	if err == nil || errors.Is(err, errRetryable) || errors.Is(err, errRemoteNotFound) ||
+		errors.Is(err, errUnauthorized) || errors.Is(err, errRangeNotSatisfiable) {
+		return err
+	}
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= http.StatusInternalServerError:
+			return fmt.Errorf("%w: %w", errRetryable, err)
+		case apiErr.Code == http.StatusNotFound:
+			return fmt.Errorf("%w: %w", errRemoteNotFound, err)
+		case apiErr.Code == http.StatusUnauthorized:
+			return fmt.Errorf("%w: %w", errUnauthorized, err)
+		case apiErr.Code == http.StatusRequestedRangeNotSatisfiable:
+			return fmt.Errorf("%w: %w", errRangeNotSatisfiable, err)
+		case apiErr.Code == http.StatusForbidden:
+			for _, item := range apiErr.Errors {
+				if item.Reason == "rateLimitExceeded" || item.Reason == "userRateLimitExceeded" {
+					return fmt.Errorf("%w: %w", errRetryable, err)
+				}
+			}
+		}
+		return err
+	}
+
+	var s3Err minio.ErrorResponse
+	if errors.As(err, &s3Err) {
+		switch {
+		case s3Err.StatusCode == http.StatusTooManyRequests || s3Err.StatusCode >= http.StatusInternalServerError ||
+			s3Err.Code == "SlowDown":
+			return fmt.Errorf("%w: %w", errRetryable, err)
+		case s3Err.StatusCode == http.StatusNotFound && s3Err.Code != "NoSuchBucket":
+			return fmt.Errorf("%w: %w", errRemoteNotFound, err)
+		case s3Err.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+			return fmt.Errorf("%w: %w", errRangeNotSatisfiable, err)
+		}
+		return err
+	}
+
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) {
+		if retrieveErr.Response != nil && (retrieveErr.Response.StatusCode == http.StatusTooManyRequests ||
+			retrieveErr.Response.StatusCode >= http.StatusInternalServerError) {
+			return fmt.Errorf("%w: %w", errRetryable, err)
+		}
+		return fmt.Errorf("%w: %w", errUnauthorized, err)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: %w", errRetryable, err)
+	}
+	return err
+}
+
+// retryRemote runs a backend operation, retrying it with jittered exponential
+// backoff as long as it fails with a temporary error. The returned error is
+// classified, see classifyError.
+func retryRemote(name string, op func() error) error {
+	delay := retryBaseDelay
+	for attempt := 1; ; attempt++ {
+		err := classifyError(op())
+		if err == nil || !errors.Is(err, errRetryable) || attempt == retryAttempts {
+			return err
+		}
+
+		wait := delay/2 + time.Duration(rand.Int63n(int64(delay)))
+		log.Printf("%s failed (attempt %d of %d), retrying in %s: %v", name, attempt, retryAttempts, wait, err)
+		time.Sleep(wait)
+
+		delay *= 2
+		if delay > retryMaxDelay {
+			delay = retryMaxDelay
+		}
+	}
+}
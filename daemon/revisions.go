@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Regis-Caelum/drive-sync/config"
+	"github.com/Regis-Caelum/drive-sync/daemon/common"
+	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
+	"io"
+	"log"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// revisionBackend is implemented by backends that keep the earlier contents
+// of a file when it is updated in place.
+type revisionBackend interface {
+	// Revisions returns the revisions of the file id, oldest first.
+	Revisions(id string) ([]*remoteRevision, error)
+
+	// DownloadRevision writes the content of a revision of the file id from
+	// offset on to w.
+	DownloadRevision(id, revisionID string, offset int64, w io.Writer) error
+
+	// KeepHeadRevision keeps the current revision of the file id from being
+	// purged.
+	KeepHeadRevision(id string) error
+}
+
+// remoteRevision describes an earlier content of a remote file.
+type remoteRevision struct {
+	ID           string
+	ModifiedTime time.Time
+	Size         int64
+	MD5          string
+	KeepForever  bool
+}
+
+var errNoRevisions = errors.New("the backend keeps no revisions")
+
+// keepRevisionPaths returns the paths of the keep_revisions setting. Every
+// revision uploaded for files below them is kept.
+func keepRevisionPaths() []string {
+	var paths []string
+	for _, path := range config.Current().KeepRevisions {
+		if path != "" {
+			paths = append(paths, filepath.Clean(path))
+		}
+	}
+	return paths
+}
+
+// keepRevision marks the revision just uploaded for f as kept forever when f
+// lies below one of the paths of the keep_revisions setting.
+func keepRevision(f *pb.Node, id string) {
+	rb, ok := backend.(revisionBackend)
+	if !ok {
+		return
+	}
+	for _, path := range keepRevisionPaths() {
+		if !common.InTree(f.GetAbsolutePath(), path) {
+			continue
+		}
+		err := retryRemote("Keep revision of "+f.GetAbsolutePath(), func() error {
+			return rb.KeepHeadRevision(id)
+		})
+		if err != nil {
+			log.Printf("Unable to keep the revision of %s: %v", f.GetAbsolutePath(), err)
+		}
+		return
+	}
+}
+
+// listRevisions returns the revisions of the file at path.
+func listRevisions(path string) ([]*remoteRevision, error) {
+	rb, ok := backend.(revisionBackend)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errNoRevisions, backend.Name())
+	}
+	entry, _, err := findRemotePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if entry.IsDir {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+
+	var revisions []*remoteRevision
+	err = retryRemote("List revisions of "+path, func() (err error) {
+		revisions, err = rb.Revisions(entry.ID)
+		return err
+	})
+	return revisions, err
+}
+
+// revisionAt returns the revision of the file id on remote that was current
+// at t, or nil when the file did not exist yet.
+func revisionAt(remote Backend, id string, t time.Time) (*remoteRevision, error) {
+	rb, ok := remote.(revisionBackend)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errNoRevisions, remote.Name())
+	}
+
+	var revisions []*remoteRevision
+	err := retryRemote("List revisions of "+id, func() (err error) {
+		revisions, err = rb.Revisions(id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].ModifiedTime.Before(revisions[j].ModifiedTime) })
+
+	var current *remoteRevision
+	for _, r := range revisions {
+		if r.ModifiedTime.After(t) {
+			break
+		}
+		current = r
+	}
+	return current, nil
+}
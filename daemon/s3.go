@@ -0,0 +1,283 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Regis-Caelum/drive-sync/config"
+	"github.com/Regis-Caelum/drive-sync/daemon/common"
+	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"golang.org/x/net/context"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// minPartSize is the smallest part size S3 accepts for all but the last part
+// of a multipart upload.
+const minPartSize = 5 * 1024 * 1024
+
+// s3MD5Metadata is the metadata key the MD5 checksum of a file is stored in.
+const s3MD5Metadata = "Md5"
+
+// maxCopySize is the largest object S3 copies in a single request, larger
+// ones are copied part by part.
+const maxCopySize = 5 * 1024 * 1024 * 1024
+
+// s3Backend mirrors the watched trees into a bucket of an S3 compatible
+// object store like AWS, MinIO or Ceph RGW. Entries are addressed by their
+// object key. S3 has no folders, they are kept as empty objects whose key
+// ends in a slash, so that they show up in the usual bucket browsers.
+type s3Backend struct {
+	client *minio.Client
+	bucket string
+}
+
+// newS3Backend connects to the bucket configured by the [backend.s3] settings
+// and creates it when it does not exist yet.
+func newS3Backend() (Backend, error) {
+	settings := config.Current().Backend.S3
+	endpoint := settings.Endpoint
+	bucket := settings.Bucket
+	if endpoint == "" || bucket == "" {
+		return nil, errors.New("endpoint and bucket of [backend.s3] have to be set")
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(settings.AccessKey, settings.SecretKey, ""),
+		Secure: !settings.Insecure,
+		Region: settings.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create s3 client: %w", err)
+	}
+
+	ctx := context.Background()
+	exists, err := client.BucketExists(ctx, bucket)
+	if err != nil {
+		return nil, fmt.Errorf("unable to check bucket %s: %w", bucket, err)
+	}
+	if !exists {
+		err = client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{Region: settings.Region})
+		if err != nil {
+			return nil, fmt.Errorf("unable to create bucket %s: %w", bucket, err)
+		}
+	}
+	return &s3Backend{client: client, bucket: bucket}, nil
+}
+
+func (b *s3Backend) Name() string {
+	return "s3"
+}
+
+func (b *s3Backend) EnsureFolder(name, parentID, localPath string) (string, error) {
+	id := path.Join(parentID, name)
+	_, err := b.client.PutObject(context.Background(), b.bucket, id+"/", http.NoBody, 0,
+		minio.PutObjectOptions{})
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (b *s3Backend) CreateFile(name, parentID string, f *pb.Node) (*remoteEntry, error) {
+	return b.UpdateFile(path.Join(parentID, name), f)
+}
+
+func (b *s3Backend) UpdateFile(id string, f *pb.Node) (*remoteEntry, error) {
+	err := b.putFile(id, f)
+	if err != nil {
+		return nil, err
+	}
+	return b.Stat(id)
+}
+
+// Move copies the object, or every object below a folder, to the new key and
+// removes the old ones, S3 cannot rename objects.
+func (b *s3Backend) Move(id, name, localPath, oldParentID, newParentID string) (string, error) {
+	ctx := context.Background()
+	newID := path.Join(newParentID, name)
+
+	var objects []minio.ObjectInfo
+	if _, err := b.client.StatObject(ctx, b.bucket, id+"/", minio.StatObjectOptions{}); err == nil {
+		for object := range b.client.ListObjects(ctx, b.bucket, minio.ListObjectsOptions{Prefix: id + "/", Recursive: true}) {
+			if object.Err != nil {
+				return "", object.Err
+			}
+			objects = append(objects, object)
+		}
+	} else {
+		object, err := b.client.StatObject(ctx, b.bucket, id, minio.StatObjectOptions{})
+		if err != nil {
+			return "", err
+		}
+		objects = append(objects, object)
+	}
+
+	for _, object := range objects {
+		dst := minio.CopyDestOptions{Bucket: b.bucket, Object: newID + strings.TrimPrefix(object.Key, id)}
+		src := minio.CopySrcOptions{Bucket: b.bucket, Object: object.Key}
+		var err error
+		if object.Size > maxCopySize {
+			_, err = b.client.ComposeObject(ctx, dst, src)
+		} else {
+			_, err = b.client.CopyObject(ctx, dst, src)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	for _, object := range objects {
+		err := b.client.RemoveObject(ctx, b.bucket, object.Key, minio.RemoveObjectOptions{})
+		if err != nil {
+			return "", err
+		}
+	}
+	return newID, nil
+}
+
+func (b *s3Backend) Delete(id string) error {
+	ctx := context.Background()
+	objects := b.client.ListObjects(ctx, b.bucket, minio.ListObjectsOptions{Prefix: id + "/", Recursive: true})
+	for result := range b.client.RemoveObjects(ctx, b.bucket, objects, minio.RemoveObjectsOptions{}) {
+		if result.Err != nil {
+			return result.Err
+		}
+	}
+	return b.client.RemoveObject(ctx, b.bucket, id, minio.RemoveObjectOptions{})
+}
+
+func (b *s3Backend) Exists(id string) (bool, error) {
+	for _, key := range []string{id, id + "/"} {
+		_, err := b.client.StatObject(context.Background(), b.bucket, key, minio.StatObjectOptions{})
+		if err == nil {
+			return true, nil
+		}
+		if minio.ToErrorResponse(err).StatusCode != http.StatusNotFound {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// Stat takes the checksum from the metadata stored by putFile, the ETag of a
+// multipart upload is no MD5 of the content.
+func (b *s3Backend) Stat(id string) (*remoteEntry, error) {
+	ctx := context.Background()
+	object, err := b.client.StatObject(ctx, b.bucket, id, minio.StatObjectOptions{})
+	if err == nil {
+		entry := &remoteEntry{
+			ID:           id,
+			Name:         path.Base(id),
+			Size:         object.Size,
+			ModifiedTime: object.LastModified,
+		}
+		for key, value := range object.UserMetadata {
+			if strings.EqualFold(key, s3MD5Metadata) {
+				entry.MD5 = value
+			}
+		}
+		return entry, nil
+	}
+	if minio.ToErrorResponse(err).StatusCode != http.StatusNotFound {
+		return nil, err
+	}
+
+	object, err = b.client.StatObject(ctx, b.bucket, id+"/", minio.StatObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &remoteEntry{ID: id, Name: path.Base(id), IsDir: true, ModifiedTime: object.LastModified}, nil
+}
+
+func (b *s3Backend) Lookup(name, parentID string) (*remoteEntry, error) {
+	entry, err := b.Stat(path.Join(parentID, name))
+	if minio.ToErrorResponse(err).StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	return entry, err
+}
+
+func (b *s3Backend) Download(id string, offset int64, w io.Writer) error {
+	opts := minio.GetObjectOptions{}
+	if offset > 0 {
+		err := opts.SetRange(offset, 0)
+		if err != nil {
+			return err
+		}
+	}
+	object, err := b.client.GetObject(context.Background(), b.bucket, id, opts)
+	if err != nil {
+		return err
+	}
+	defer object.Close()
+
+	_, err = io.Copy(w, object)
+	return err
+}
+
+func (b *s3Backend) List(id string) ([]*remoteEntry, error) {
+	prefix := ""
+	if id != "" {
+		prefix = id + "/"
+	}
+
+	var entries []*remoteEntry
+	for object := range b.client.ListObjects(context.Background(), b.bucket, minio.ListObjectsOptions{Prefix: prefix}) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("unable to list %s: %w", id, object.Err)
+		}
+		if object.Key == prefix {
+			continue
+		}
+		key := strings.TrimSuffix(object.Key, "/")
+		entries = append(entries, &remoteEntry{
+			ID:           key,
+			Name:         path.Base(key),
+			IsDir:        strings.HasSuffix(object.Key, "/"),
+			Size:         object.Size,
+			ModifiedTime: object.LastModified,
+		})
+	}
+	return entries, nil
+}
+
+// putFile uploads the content of the node to the object id. Files larger than
+// one part are sent as a multipart upload.
+func (b *s3Backend) putFile(id string, f *pb.Node) error {
+	localFile, err := os.Open(f.GetAbsolutePath())
+	if err != nil {
+		return fmt.Errorf("unable to open local file: %w", err)
+	}
+	defer localFile.Close()
+
+	info, err := localFile.Stat()
+	if err != nil {
+		return err
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(f.GetName()))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	sum, err := common.FileMD5(f.GetAbsolutePath())
+	if err != nil {
+		return err
+	}
+	partSize := uploadChunkSize()
+	if partSize < minPartSize {
+		partSize = minPartSize
+	}
+
+	_, err = b.client.PutObject(context.Background(), b.bucket, id, throttleUpload(localFile), info.Size(), minio.PutObjectOptions{
+		ContentType:  contentType,
+		PartSize:     uint64(partSize),
+		UserMetadata: map[string]string{s3MD5Metadata: sum},
+	})
+	return err
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Regis-Caelum/drive-sync/config"
+	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"io"
+	"io/fs"
+	"log"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+)
+
+// sftpBackend mirrors the watched trees into a directory on an SSH server.
+// Entries are addressed by their slash separated path relative to the remote
+// root. The connection is opened on first use and opened again after it broke.
+type sftpBackend struct {
+	addr   string
+	root   string
+	config *ssh.ClientConfig
+
+	mutex  sync.Mutex
+	ssh    *ssh.Client
+	client *sftp.Client
+
+	// agent is the connection to the SSH agent opened for the handshake in
+	// progress. It is closed once the handshake is done.
+	agent net.Conn
+}
+
+// newSftpBackend sets up the connection settings from the [backend.sftp]
+// settings. Keys are taken from the key setting,
+// the default key files in ~/.ssh and the agent behind SSH_AUTH_SOCK, the
+// server key is checked against known_hosts.
+func newSftpBackend() (Backend, error) {
+	settings := config.Current().Backend.SFTP
+	addr := settings.Host
+	if addr == "" {
+		return nil, errors.New("host of [backend.sftp] is not set")
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "22")
+	}
+
+	user := settings.User
+	if user == "" {
+		user = os.Getenv("USER")
+	}
+	root := settings.Root
+	if root == "" {
+		root = "."
+	}
+
+	home, _ := os.UserHomeDir()
+	knownHostsFile := settings.KnownHosts
+	if knownHostsFile == "" {
+		knownHostsFile = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	hostKeyCallback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read known hosts: %w", err)
+	}
+
+	var signers []ssh.Signer
+	keyFiles := []string{settings.Key}
+	if keyFiles[0] == "" {
+		keyFiles = []string{filepath.Join(home, ".ssh", "id_ed25519"), filepath.Join(home, ".ssh", "id_ecdsa"),
+			filepath.Join(home, ".ssh", "id_rsa")}
+	}
+	for _, keyFile := range keyFiles {
+		key, err := os.ReadFile(keyFile)
+		if errors.Is(err, fs.ErrNotExist) && settings.Key == "" {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to read ssh key: %w", err)
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			log.Printf("Unable to use ssh key %s: %v", keyFile, err)
+			continue
+		}
+		signers = append(signers, signer)
+	}
+
+	// The SSH client tries every method only once, so the keys of the agent
+	// are offered together with the key files.
+	b := &sftpBackend{addr: addr, root: root}
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	keys := ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+		if socket == "" {
+			return signers, nil
+		}
+		conn, err := net.Dial("unix", socket)
+		if err != nil {
+			log.Printf("Unable to use the ssh agent: %v", err)
+			return signers, nil
+		}
+		b.agent = conn
+		agentSigners, err := agent.NewClient(conn).Signers()
+		if err != nil {
+			log.Printf("Unable to use the ssh agent: %v", err)
+		}
+		return append(signers[:len(signers):len(signers)], agentSigners...), nil
+	})
+	b.config = &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{keys},
+		HostKeyCallback: hostKeyCallback,
+	}
+	return b, nil
+}
+
+func (b *sftpBackend) Name() string {
+	return "sftp"
+}
+
+func (b *sftpBackend) EnsureFolder(name, parentID, localPath string) (string, error) {
+	client, err := b.connect()
+	if err != nil {
+		return "", err
+	}
+	id := path.Join(parentID, name)
+	return id, b.wrap(client.MkdirAll(b.path(id)))
+}
+
+func (b *sftpBackend) CreateFile(name, parentID string, f *pb.Node) (*remoteEntry, error) {
+	return b.UpdateFile(path.Join(parentID, name), f)
+}
+
+func (b *sftpBackend) UpdateFile(id string, f *pb.Node) (*remoteEntry, error) {
+	err := b.putFile(id, f)
+	if err != nil {
+		return nil, err
+	}
+	return b.Stat(id)
+}
+
+func (b *sftpBackend) Move(id, name, localPath, oldParentID, newParentID string) (string, error) {
+	client, err := b.connect()
+	if err != nil {
+		return "", err
+	}
+	newID := path.Join(newParentID, name)
+	return newID, b.wrap(b.rename(client, b.path(id), b.path(newID)))
+}
+
+func (b *sftpBackend) Delete(id string) error {
+	client, err := b.connect()
+	if err != nil {
+		return err
+	}
+	_, err = client.Lstat(b.path(id))
+	if err != nil {
+		return b.wrap(err)
+	}
+	return b.wrap(client.RemoveAll(b.path(id)))
+}
+
+func (b *sftpBackend) Exists(id string) (bool, error) {
+	client, err := b.connect()
+	if err != nil {
+		return false, err
+	}
+	_, err = client.Lstat(b.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, b.wrap(err)
+}
+
+func (b *sftpBackend) Stat(id string) (*remoteEntry, error) {
+	client, err := b.connect()
+	if err != nil {
+		return nil, err
+	}
+	info, err := client.Stat(b.path(id))
+	if err != nil {
+		return nil, b.wrap(err)
+	}
+	return &remoteEntry{
+		ID:           id,
+		Name:         info.Name(),
+		IsDir:        info.IsDir(),
+		Size:         info.Size(),
+		ModifiedTime: info.ModTime(),
+	}, nil
+}
+
+func (b *sftpBackend) Lookup(name, parentID string) (*remoteEntry, error) {
+	entry, err := b.Stat(path.Join(parentID, name))
+	if errors.Is(err, errRemoteNotFound) {
+		return nil, nil
+	}
+	return entry, err
+}
+
+func (b *sftpBackend) Download(id string, offset int64, w io.Writer) error {
+	client, err := b.connect()
+	if err != nil {
+		return err
+	}
+	file, err := client.Open(b.path(id))
+	if err != nil {
+		return b.wrap(err)
+	}
+	defer file.Close()
+
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		return b.wrap(err)
+	}
+	_, err = file.WriteTo(w)
+	return b.wrap(err)
+}
+
+func (b *sftpBackend) List(id string) ([]*remoteEntry, error) {
+	client, err := b.connect()
+	if err != nil {
+		return nil, err
+	}
+	infos, err := client.ReadDir(b.path(id))
+	if err != nil {
+		return nil, b.wrap(err)
+	}
+
+	var entries []*remoteEntry
+	for _, info := range infos {
+		entries = append(entries, &remoteEntry{
+			ID:           path.Join(id, info.Name()),
+			Name:         info.Name(),
+			IsDir:        info.IsDir(),
+			Size:         info.Size(),
+			ModifiedTime: info.ModTime(),
+		})
+	}
+	return entries, nil
+}
+
+func (b *sftpBackend) path(id string) string {
+	return path.Join(b.root, id)
+}
+
+// putFile replaces the file id with the content of the node. The content is
+// written to a temporary file first, so the file is never left half written.
+func (b *sftpBackend) putFile(id string, f *pb.Node) error {
+	client, err := b.connect()
+	if err != nil {
+		return err
+	}
+
+	src, err := os.Open(f.GetAbsolutePath())
+	if err != nil {
+		return fmt.Errorf("unable to open local file: %w", err)
+	}
+	defer src.Close()
+
+	target := b.path(id)
+	temp := path.Join(path.Dir(target), ".dsync-"+path.Base(target))
+	dst, err := client.Create(temp)
+	if err != nil {
+		return b.wrap(err)
+	}
+
+	_, err = dst.ReadFrom(throttleUpload(src))
+	if err == nil {
+		err = dst.Close()
+	} else {
+		dst.Close()
+	}
+	if err == nil {
+		err = b.rename(client, temp, target)
+	}
+	if err != nil {
+		client.Remove(temp)
+		return b.wrap(err)
+	}
+	return nil
+}
+
+// rename moves oldPath to newPath, replacing newPath when it exists. Servers
+// without the posix-rename extension refuse to replace files on a rename.
+func (b *sftpBackend) rename(client *sftp.Client, oldPath, newPath string) error {
+	if _, ok := client.HasExtension("posix-rename@openssh.com"); ok {
+		return client.PosixRename(oldPath, newPath)
+	}
+	err := client.Rename(oldPath, newPath)
+	if err != nil {
+		if _, statErr := client.Lstat(newPath); statErr == nil {
+			client.Remove(newPath)
+			err = client.Rename(oldPath, newPath)
+		}
+	}
+	return err
+}
+
+// connect returns the SFTP session, connecting to the server when there is
+// none.
+func (b *sftpBackend) connect() (*sftp.Client, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.client != nil {
+		return b.client, nil
+	}
+
+	conn, err := ssh.Dial("tcp", b.addr, b.config)
+	if b.agent != nil {
+		b.agent.Close()
+		b.agent = nil
+	}
+	if err != nil {
+		return nil, b.classifyDialError(err)
+	}
+	client, err := sftp.NewClient(conn, sftp.UseConcurrentWrites(true))
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("%w: unable to start sftp session: %w", errRetryable, err)
+	}
+	b.ssh = conn
+	b.client = client
+	return client, nil
+}
+
+// disconnect drops a broken connection so that the next call opens a new one.
+func (b *sftpBackend) disconnect() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.client != nil {
+		b.client.Close()
+		b.ssh.Close()
+		b.client = nil
+		b.ssh = nil
+	}
+}
+
+func (b *sftpBackend) classifyDialError(err error) error {
+	var keyErr *knownhosts.KeyError
+	if errors.As(err, &keyErr) {
+		return fmt.Errorf("host key of %s does not match known hosts: %w", b.addr, err)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return fmt.Errorf("%w: %w", errRetryable, err)
+	}
+	return fmt.Errorf("unable to connect to %s: %w", b.addr, err)
+}
+
+// wrap marks errors about missing entries so that the recorded remote state
+// gets repaired, and drops the connection when it broke.
+func (b *sftpBackend) wrap(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("%w: %w", errRemoteNotFound, err)
+	case errors.Is(err, sftp.ErrSSHFxConnectionLost) || errors.Is(err, io.EOF):
+		b.disconnect()
+		return fmt.Errorf("%w: %w", errRetryable, err)
+	}
+	return err
+}
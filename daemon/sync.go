@@ -0,0 +1,575 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Regis-Caelum/drive-sync/daemon/database"
+	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
+	"google.golang.org/protobuf/proto"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// remoteFolderMutex serializes folder creation. Workers share parent folders,
+// so only one of them may create folders at a time or the backend ends up
+// with duplicates.
+var remoteFolderMutex sync.Mutex
+
+// remoteMutex guards backend, the Drive client and token against being
+// replaced while they are in use. Jobs and requests talking to the backend
+// hold it for reading, a login or logout holds it for writing and so waits
+// for running jobs to finish.
+var remoteMutex sync.RWMutex
+
+// tokenMutex guards the fields of token and the token itself against being
+// changed while another goroutine reads or stores them. The access token is
+// refreshed on whichever goroutine makes a Drive request, jobs only hold
+// remoteMutex for reading.
+var tokenMutex sync.Mutex
+
+// remoteAuthRequired is set once the backend rejected the stored credentials.
+// Workers and the poller set it while holding remoteMutex only for reading.
+var remoteAuthRequired atomic.Bool
+
+// syncRemote queues everything that is not in sync with the backend yet.
+func syncRemote() {
+	if !remoteReady() {
+		// The workers set up the host folder once the backend can be reached.
+		return
+	}
+
+	syncFolders()
+	syncFiles()
+}
+
+// remoteReady reports whether queued jobs can be run against the backend. It
+// creates the Computers and host folders on first use.
+func remoteReady() bool {
+	if backend == nil || remoteAuthRequired.Load() {
+		return false
+	}
+	if hostFolder() != "" {
+		return true
+	}
+
+	err := ensureHostFolder()
+	if err != nil {
+		log.Printf("Unable to create host folder: %v", err)
+		if errors.Is(err, errUnauthorized) {
+			requireLogin(err)
+		}
+		return false
+	}
+	return true
+}
+
+// ensureHostFolder creates the Computers/<hostname> folder that mirrors the
+// local file system and stores the IDs with the token.
+func ensureHostFolder() error {
+	remoteFolderMutex.Lock()
+	defer remoteFolderMutex.Unlock()
+
+	if rootFolder() == "" {
+		var rootID string
+		err := retryRemote("Create root folder", func() (err error) {
+			rootID, err = backend.EnsureFolder("Computers", "", "")
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("unable to create root folder: %w", err)
+		}
+
+		err = updateToken(func() { token.Root = rootID })
+		if err != nil {
+			log.Printf("Unable to store the root folder: %v", err)
+		}
+	}
+
+	if hostFolder() == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("unable to get hostname: %w", err)
+		}
+
+		var hostID string
+		err = retryRemote("Create host folder", func() (err error) {
+			hostID, err = backend.EnsureFolder(hostname, rootFolder(), "")
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("unable to create host folder: %w", err)
+		}
+
+		err = updateToken(func() { token.Host = hostID })
+		if err != nil {
+			log.Printf("Unable to store the host folder: %v", err)
+		}
+	}
+	return nil
+}
+
+// storeToken persists the token together with the root and host folder IDs.
+// Its value is stored sealed, the token in memory stays readable.
+func storeToken() error {
+	tokenMutex.Lock()
+	defer tokenMutex.Unlock()
+	return writeToken()
+}
+
+// updateToken applies update to the token and stores it, both under
+// tokenMutex.
+func updateToken(update func()) error {
+	tokenMutex.Lock()
+	defer tokenMutex.Unlock()
+	update()
+	return writeToken()
+}
+
+// rootFolder returns the ID of the Computers folder. Workers set it up and
+// repair it while holding remoteMutex only for reading.
+func rootFolder() string {
+	tokenMutex.Lock()
+	defer tokenMutex.Unlock()
+	return token.GetRoot()
+}
+
+// hostFolder returns the ID of the Computers/<hostname> folder, see
+// rootFolder.
+func hostFolder() string {
+	tokenMutex.Lock()
+	defer tokenMutex.Unlock()
+	return token.GetHost()
+}
+
+// tokenValue returns the token value, which a refresh may replace at any time.
+func tokenValue() string {
+	tokenMutex.Lock()
+	defer tokenMutex.Unlock()
+	return token.GetValue()
+}
+
+// writeToken stores the token. The caller holds tokenMutex.
+func writeToken() error {
+	value, err := sealStored(token.GetValue(), unreadableToken)
+	var client string
+	if err == nil {
+		client, err = sealStored(token.GetClient(), unreadableClient)
+	}
+	if err != nil {
+		log.Printf("Unable to encrypt the token: %v", err)
+		return err
+	}
+	stored := proto.Clone(token).(*pb.OAuth2Token)
+	stored.Value = value
+	stored.Client = client
+
+	tx, err := database.GetTx()
+	log.Println("Transaction started")
+	if err != nil {
+		fmt.Printf("Unable to get transaction: %v", err)
+		return err
+	}
+	defer database.RollbackTx(tx)
+
+	tx.Save(stored)
+	database.CommitTx(tx)
+	log.Println("Transaction Ended")
+	return nil
+}
+
+// requireLogin stops all remote work after the stored credentials were
+// rejected. The token is kept, a rejection may be temporary, until
+// `dsync login` replaces it.
+func requireLogin(cause error) {
+	if remoteAuthRequired.Swap(true) {
+		return
+	}
+	if isServiceAccountKey(tokenValue()) {
+		// The key is the only copy the daemon has, it must survive this.
+		log.Printf("%s rejected the service account key, check the key and its delegation and "+
+			"run `dsync login --service-account` again: %v", backend.Name(), cause)
+		return
+	}
+	if _, ok := backend.(driveBackend); !ok {
+		log.Printf("%s rejected the credentials, correct them in the configuration and restart the daemon: %v",
+			backend.Name(), cause)
+		return
+	}
+	log.Printf("%s rejected the stored credentials, run `dsync login` to log in again: %v", backend.Name(), cause)
+}
+
+// relogin sets the backend up again with a token that was just saved and lets
+// the workers resume the jobs that waited for it. Until the backend is ready
+// no job runs, neither against the old backend nor the new one.
+func relogin() {
+	remoteMutex.Lock()
+	backend = nil
+	remoteAuthRequired.Store(false)
+	initBackend()
+	remoteMutex.Unlock()
+	notifyWorkers()
+}
+
+// repairRemote is called when the backend reports an entry below path as
+// missing, for instance because it was deleted in the Drive web UI. It finds
+// the outermost recorded folder or file that is gone, forgets the remote IDs
+// recorded at and below it and queues everything there for upload again.
+func repairRemote(path string) {
+	missing := ""
+	if !remoteExists(rootFolder()) || !remoteExists(hostFolder()) {
+		log.Println("Host folder is missing, uploading everything again")
+		remoteFolderMutex.Lock()
+		rootExists := remoteExists(rootFolder())
+		tokenMutex.Lock()
+		if !rootExists {
+			token.Root = ""
+		}
+		token.Host = ""
+		tokenMutex.Unlock()
+		remoteFolderMutex.Unlock()
+		missing = "/"
+	}
+
+	descPath := ""
+	for _, part := range strings.Split(path, "/") {
+		if missing != "" || part == "" {
+			continue
+		}
+		descPath += "/" + part
+		rec, err := database.GetDriveRecordByLocalPath(descPath)
+		if err == nil && !remoteExists(rec.GetDriveId()) {
+			missing = descPath
+		}
+	}
+	if missing == "" {
+		missing = path
+	}
+
+	log.Printf("Remote entry for %s is missing, uploading it again", missing)
+	err := database.ForgetRemoteTree(missing)
+	if err != nil {
+		log.Println("Error:", err)
+		return
+	}
+
+	watchList, err := database.GetWatchListInTree(missing)
+	if err != nil {
+		log.Println("Error:", err)
+	}
+	for _, w := range watchList {
+		enqueueJob(&pb.Job{Action: pb.JOB_ACTION_SYNC_FOLDER, Path: w.GetAbsolutePath()})
+	}
+
+	nodes, err := database.GetNodesInTree(missing)
+	if err != nil {
+		log.Println("Error:", err)
+	}
+	for _, n := range nodes {
+		enqueueJob(&pb.Job{Action: pb.JOB_ACTION_SYNC_FILE, Path: n.GetAbsolutePath()})
+	}
+}
+
+// remoteExists reports whether a remote entry still exists. Errors count as
+// existing, so that a flaky connection never triggers a repair.
+func remoteExists(id string) bool {
+	if id == "" {
+		return false
+	}
+
+	exists := true
+	err := retryRemote("Check "+id, func() (err error) {
+		exists, err = backend.Exists(id)
+		return err
+	})
+	return err != nil || exists
+}
+
+func syncFolders() {
+	fmt.Println("Syncing Folders:")
+	watchList, _ := database.ListAllWatchLists()
+	if len(watchList) != 0 {
+		for _, w := range watchList {
+			_, err := database.GetDriveRecordByLocalPath(w.GetAbsolutePath())
+			if err != nil {
+				enqueueJob(&pb.Job{Action: pb.JOB_ACTION_SYNC_FOLDER, Path: w.GetAbsolutePath()})
+			}
+		}
+	}
+}
+
+func syncFolder(w *pb.WatchList) error {
+	driveID, err := ensureFolderPath(w.GetAbsolutePath())
+	if err != nil {
+		return err
+	}
+	w.DriveId = driveID
+	err = database.UpdateWatchList(w)
+	if err != nil {
+		fmt.Printf("Unable to update watch list: %v", err)
+	}
+	return nil
+}
+
+// ensureFolderPath creates the remote folders mirroring every segment of
+// absPath below the host folder and returns the ID of the deepest one.
+func ensureFolderPath(absPath string) (string, error) {
+	remoteFolderMutex.Lock()
+	defer remoteFolderMutex.Unlock()
+
+	descPath := ""
+	pathParts := strings.Split(absPath, "/")
+	currentParentID := hostFolder()
+	for _, part := range pathParts {
+		if part == "" {
+			continue
+		}
+		descPath += "/" + part
+		if rec, err := database.GetDriveRecordByLocalPath(descPath); err == nil {
+			currentParentID = rec.DriveId
+			continue
+		}
+
+		var folderID string
+		err := retryRemote("Create folder "+descPath, func() (err error) {
+			folderID, err = backend.EnsureFolder(part, currentParentID, descPath)
+			return err
+		})
+		if err != nil {
+			return "", fmt.Errorf("unable to create folder %s: %w", descPath, err)
+		}
+		log.Printf("Host folder created: %s (%s)\n", part, folderID)
+		err = database.CreateDriveRecord(&pb.DriveRecord{
+			Name:      part,
+			LocalPath: descPath,
+			DriveId:   folderID,
+			ParentId:  currentParentID,
+		})
+		if err != nil {
+			fmt.Printf("Unable to update watch list: %v", err)
+		}
+		currentParentID = folderID
+	}
+	return currentParentID, nil
+}
+
+func syncFiles() {
+	fmt.Println("Syncing Files:")
+	fileNodes, _ := database.ListAllNodes()
+	if len(fileNodes) != 0 {
+		for _, f := range fileNodes {
+			if f.GetUploadStatus() == pb.FILE_STATUS_NOT_UPLOADED || f.GetFileStatus() == pb.FILE_STATUS_MODIFIED ||
+				f.GetUploadSession() != "" {
+				enqueueJob(&pb.Job{Action: pb.JOB_ACTION_SYNC_FILE, Path: f.GetAbsolutePath()})
+			}
+		}
+	}
+}
+
+func syncFile(f *pb.Node) error {
+	if f.GetUploadStatus() == pb.FILE_STATUS_CONFLICT {
+		// Held until it is resolved with `dsync conflicts resolve`.
+		return nil
+	}
+	rec, err := database.GetDriveRecordByLocalPath(f.GetAbsolutePath())
+	if err == nil && f.GetFileStatus() != pb.FILE_STATUS_MODIFIED && f.GetUploadSession() == "" {
+		return nil
+	}
+	if err == nil {
+		return syncModifiedFile(f, rec)
+	}
+
+	parentID, err := ensureFolderPath(filepath.Dir(f.GetAbsolutePath()))
+	if err != nil {
+		return err
+	}
+
+	// A file of the same name may exist remotely already, uploaded by another
+	// machine or before the local database was lost.
+	var existing *remoteEntry
+	err = retryRemote("Check "+f.GetAbsolutePath(), func() (err error) {
+		existing, err = backend.Lookup(f.GetName(), parentID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if existing != nil && !existing.IsDir {
+		rec = &pb.DriveRecord{
+			Name:      f.GetName(),
+			LocalPath: f.GetAbsolutePath(),
+			DriveId:   existing.ID,
+			ParentId:  parentID,
+		}
+		err = database.CreateDriveRecord(rec)
+		if err != nil {
+			return err
+		}
+		if sameContent(f, existing) {
+			fmt.Printf("File already uploaded: %s (%s)\n", f.GetName(), existing.ID)
+			err = beginUpload(f)
+			if err != nil {
+				return err
+			}
+			finishUpload(f, existing)
+			return nil
+		}
+		return resolveConflict(f, rec, existing, conflictPolicy())
+	}
+
+	err = beginUpload(f)
+	if err != nil {
+		return err
+	}
+	var remote *remoteEntry
+	err = retryRemote("Upload "+f.GetAbsolutePath(), func() (err error) {
+		remote, err = backend.CreateFile(f.GetName(), parentID, f)
+		return err
+	})
+	if err != nil {
+		abortUpload(f)
+		return fmt.Errorf("unable to create file: %w", err)
+	}
+	fmt.Printf("File created: %s (%s)\n", f.GetName(), remote.ID)
+	finishUpload(f, remote)
+	keepRevision(f, remote.ID)
+	err = database.CreateDriveRecord(&pb.DriveRecord{
+		Name:      f.GetName(),
+		LocalPath: f.GetAbsolutePath(),
+		DriveId:   remote.ID,
+		ParentId:  parentID,
+	})
+	if err != nil {
+		fmt.Printf("Unable to update watch list: %v", err)
+	}
+	return nil
+}
+
+// syncModifiedFile pushes the new content of an already uploaded file to its
+// existing remote file, so the file keeps its ID and revision history. When
+// the remote file changed as well since the last sync, the conflict policy
+// decides which version is kept.
+func syncModifiedFile(f *pb.Node, rec *pb.DriveRecord) error {
+	var remote *remoteEntry
+	err := retryRemote("Check "+f.GetAbsolutePath(), func() (err error) {
+		remote, err = backend.Stat(rec.GetDriveId())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if remoteChanged(f, remote) {
+		return resolveConflict(f, rec, remote, conflictPolicy())
+	}
+	return updateFile(f, rec.GetDriveId())
+}
+
+// updateFile replaces the content of the remote file id with the local one.
+func updateFile(f *pb.Node, id string) error {
+	err := beginUpload(f)
+	if err != nil {
+		return err
+	}
+
+	var remote *remoteEntry
+	err = retryRemote("Upload "+f.GetAbsolutePath(), func() (err error) {
+		remote, err = backend.UpdateFile(id, f)
+		return err
+	})
+	if err != nil {
+		abortUpload(f)
+		return fmt.Errorf("unable to update file: %w", err)
+	}
+	fmt.Printf("File updated: %s (%s)\n", f.GetName(), remote.ID)
+	finishUpload(f, remote)
+	keepRevision(f, remote.ID)
+	return nil
+}
+
+// beginUpload marks the node as unmodified before its content is read, so
+// that a write landing during the upload flags it as modified again.
+func beginUpload(f *pb.Node) error {
+	f.FileStatus = pb.FILE_STATUS_UNMODIFIED
+	return database.UpdateNodeFields(f.GetId(), map[string]interface{}{"file_status": f.FileStatus})
+}
+
+// abortUpload flags the node as modified again after a failed upload.
+func abortUpload(f *pb.Node) {
+	f.FileStatus = pb.FILE_STATUS_MODIFIED
+	err := database.UpdateNodeFields(f.GetId(), map[string]interface{}{"file_status": f.FileStatus})
+	if err != nil {
+		fmt.Printf("Unable to update node: %v", err)
+	}
+}
+
+// finishUpload records the remote file that now holds the node's content, and
+// its state to detect later remote changes.
+func finishUpload(f *pb.Node, remote *remoteEntry) {
+	f.DriveId = remote.ID
+	f.UploadStatus = pb.FILE_STATUS_UPLOADED
+	f.RemoteMd5 = remote.MD5
+	f.RemoteModifiedTime = formatRemoteTime(remote.ModifiedTime)
+	f.Error = ""
+	err := database.UpdateNodeFields(f.GetId(), map[string]interface{}{
+		"drive_id":             f.DriveId,
+		"upload_status":        f.UploadStatus,
+		"remote_md5":           f.RemoteMd5,
+		"remote_modified_time": f.RemoteModifiedTime,
+		"error":                f.Error,
+	})
+	if err != nil {
+		fmt.Printf("Unable to update node: %v", err)
+	}
+}
+
+// syncMove applies a queued move to the remote entry and records its new
+// parent folder.
+func syncMove(job *pb.Job) error {
+	newParentID, err := ensureFolderPath(filepath.Dir(job.GetPath()))
+	if err != nil {
+		return err
+	}
+
+	var newID string
+	err = retryRemote("Move "+job.GetPath(), func() (err error) {
+		newID, err = backend.Move(job.GetDriveId(), filepath.Base(job.GetPath()), job.GetPath(), job.GetParentId(), newParentID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("File moved: %s (%s)\n", job.GetPath(), newID)
+
+	if newID != job.GetDriveId() {
+		err = database.RewriteRemoteIDs(job.GetDriveId(), newID)
+		if err != nil {
+			return err
+		}
+	}
+
+	rec, err := database.GetDriveRecordByLocalPath(job.GetPath())
+	if err != nil {
+		return nil
+	}
+	rec.ParentId = newParentID
+	return database.UpdateDriveRecord(rec)
+}
+
+func deleteRemote(driveID, name string) error {
+	err := retryRemote("Delete "+name, func() error {
+		return backend.Delete(driveID)
+	})
+	if errors.Is(err, errRemoteNotFound) {
+		fmt.Printf("File with ID %s, %s was already deleted\n", driveID, name)
+		return nil
+	}
+	if err != nil {
+		log.Printf("Failed to delete file with ID %s, %s: %v", driveID, name, err)
+		return err
+	}
+	fmt.Printf("Successfully deleted file with ID %s, %s\n", driveID, name)
+	return nil
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/Regis-Caelum/drive-sync/config"
+	"github.com/Regis-Caelum/drive-sync/daemon/common"
+	"github.com/Regis-Caelum/drive-sync/daemon/database"
+	"github.com/zalando/go-keyring"
+	"gorm.io/gorm"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// sealedTokenPrefix marks a sealed token value. The name of the key store
+	// and the base64 encoded ciphertext follow it, separated by a colon.
+	sealedTokenPrefix = "dsync-sealed:v1:"
+
+	keyringService = "dsync"
+	keyringUser    = "token-key"
+	keyringTimeout = 5 * time.Second
+	tokenKeySize   = 32
+)
+
+var (
+	tokenKeyMutex sync.Mutex
+	tokenKeys     = make(map[string][]byte)
+
+	// unreadableToken and unreadableClient are sealed values that could not
+	// be opened, for example because the key file is gone. They are written
+	// back unchanged until a new login replaces them, so that restoring the
+	// key recovers them.
+	unreadableToken  string
+	unreadableClient string
+)
+
+// loadToken reads the stored token and opens its value and client secret.
+// Values stored in plain text by an earlier version are sealed right away.
+func loadToken() error {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		tx.First(token)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var plainValue, plainClient bool
+	token.Value, unreadableToken, plainValue = openStored("token", token.GetValue())
+	token.Client, unreadableClient, plainClient = openStored("client secret", token.GetClient())
+	if plainValue || plainClient {
+		log.Println("Encrypting the stored token")
+		err = storeToken()
+		if err != nil {
+			log.Printf("The stored token remains unencrypted: %v", err)
+		}
+	}
+	return nil
+}
+
+// openStored opens a stored value. It returns the sealed value instead when
+// it can not be opened, and reports whether the value was stored in plain
+// text.
+func openStored(name, value string) (string, string, bool) {
+	switch {
+	case value == "":
+		return "", "", false
+	case strings.HasPrefix(value, sealedTokenPrefix):
+		plain, err := openToken(value)
+		if err != nil {
+			log.Printf("Unable to decrypt the stored %s, run `dsync login` to log in again: %v", name, err)
+			return "", value, false
+		}
+		return plain, "", false
+	}
+	return value, "", true
+}
+
+// sealStored returns what to store for value, or unreadable when it is empty.
+func sealStored(value, unreadable string) (string, error) {
+	if value == "" {
+		return unreadable, nil
+	}
+	return sealToken(value)
+}
+
+func sealToken(value string) (string, error) {
+	store := tokenKeyStore()
+	key, err := tokenKey(store)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := common.Seal(key, []byte(value))
+	if err != nil {
+		return "", err
+	}
+	return sealedTokenPrefix + store + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func openToken(value string) (string, error) {
+	store, data, ok := strings.Cut(strings.TrimPrefix(value, sealedTokenPrefix), ":")
+	if !ok {
+		return "", errors.New("malformed sealed token")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", err
+	}
+	key, err := tokenKey(store)
+	if err != nil {
+		return "", err
+	}
+	plain, err := common.Open(key, sealed)
+	if err != nil {
+		return "", fmt.Errorf("the %s key does not match the token: %w", store, err)
+	}
+	return string(plain), nil
+}
+
+// tokenKeyStore returns the store holding the key for newly sealed tokens.
+// Unless one is configured, the keyring is used when a session bus is
+// present and answers, the key file otherwise.
+func tokenKeyStore() string {
+	switch store := config.Current().TokenKey; store {
+	case "keyring", "file":
+		return store
+	}
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return "file"
+	}
+	_, err := tokenKey("keyring")
+	if err != nil {
+		log.Printf("Keyring unavailable, using %s: %v", config.Current().TokenKeyFile, err)
+		return "file"
+	}
+	return "keyring"
+}
+
+// tokenKey returns the key kept in store, creating it on first use. Keys are
+// cached for the lifetime of the daemon.
+func tokenKey(store string) ([]byte, error) {
+	tokenKeyMutex.Lock()
+	defer tokenKeyMutex.Unlock()
+
+	if key, ok := tokenKeys[store]; ok {
+		return key, nil
+	}
+	var key []byte
+	var err error
+	switch store {
+	case "keyring":
+		key, err = keyringTokenKey()
+	case "file":
+		key, err = fileTokenKey(config.Current().TokenKeyFile)
+	default:
+		err = fmt.Errorf("unknown key store %q", store)
+	}
+	if err != nil {
+		return nil, err
+	}
+	tokenKeys[store] = key
+	return key, nil
+}
+
+// keyringTokenKey reads the key from the Secret Service. A keyring that is
+// locked waits for the user to unlock it, so the call is given up after
+// keyringTimeout.
+func keyringTokenKey() ([]byte, error) {
+	type result struct {
+		key []byte
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		encoded, err := keyring.Get(keyringService, keyringUser)
+		if errors.Is(err, keyring.ErrNotFound) {
+			key := make([]byte, tokenKeySize)
+			_, err = rand.Read(key)
+			if err == nil {
+				encoded = base64.StdEncoding.EncodeToString(key)
+				err = keyring.Set(keyringService, keyringUser, encoded)
+			}
+		}
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err == nil && len(key) != tokenKeySize {
+			err = errors.New("the key in the keyring has the wrong size")
+		}
+		done <- result{key, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.key, r.err
+	case <-time.After(keyringTimeout):
+		return nil, errors.New("the keyring did not answer")
+	}
+}
+
+// fileTokenKey reads the key from path, or creates the file with a new key.
+// A file other users can access is refused.
+func fileTokenKey(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		key := make([]byte, tokenKeySize)
+		_, err = rand.Read(key)
+		if err != nil {
+			return nil, err
+		}
+		err = os.MkdirAll(filepath.Dir(path), 0700)
+		if err != nil {
+			return nil, err
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return nil, err
+		}
+		_, err = f.Write(key)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(path)
+			return nil, err
+		}
+		log.Printf("Created token key %s", path)
+		return key, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		return nil, fmt.Errorf("%s is accessible by other users, restrict it with chmod 600", path)
+	}
+
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != tokenKeySize {
+		return nil, fmt.Errorf("%s does not hold a %d byte key", path, tokenKeySize)
+	}
+	return key, nil
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/Regis-Caelum/drive-sync/config"
+	"github.com/Regis-Caelum/drive-sync/daemon/database"
+	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
+	"golang.org/x/net/context"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	gDriveUploadURL = "https://www.googleapis.com/upload/drive/v3/files"
+
+	// uploadChunkAlignment is the granularity Drive requires for all chunks
+	// but the last one.
+	uploadChunkAlignment = 256 * 1024
+)
+
+// uploadChunkSize returns the chunk size in bytes, configured in MiB and
+// aligned to what Drive accepts.
+func uploadChunkSize() int64 {
+	size := config.Current().UploadChunkSize
+	return size * 1024 * 1024 / uploadChunkAlignment * uploadChunkAlignment
+}
+
+// gDriveResumableUpload uploads the content of the node through a Drive
+// resumable upload session. A new file is created from metadata when fileID
+// is empty, otherwise the content of fileID is replaced. The session URI and
+// the confirmed offset are stored on the node after every chunk, so an
+// interrupted upload continues where it stopped, even after a restart.
+func gDriveResumableUpload(f *pb.Node, fileID string, metadata *drive.File) (*drive.File, error) {
+	localFile, err := os.Open(f.GetAbsolutePath())
+	if err != nil {
+		return nil, fmt.Errorf("unable to open local file: %w", err)
+	}
+	defer localFile.Close()
+
+	info, err := localFile.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	modTime := info.ModTime().UnixNano()
+
+	offset := int64(-1)
+	if f.GetUploadSession() != "" && f.GetUploadSize() == size && f.GetUploadModTime() == modTime {
+		var file *drive.File
+		file, offset, err = gDriveQueryUpload(f.GetUploadSession(), size)
+		if err != nil {
+			log.Printf("Unable to resume upload of %s, starting over: %v", f.GetAbsolutePath(), err)
+			offset = -1
+		} else if file != nil {
+			gDriveSaveUploadSession(f, "", 0, 0, 0)
+			return file, nil
+		} else {
+			log.Printf("Resuming upload of %s at byte %d of %d", f.GetAbsolutePath(), offset, size)
+		}
+	}
+
+	if offset < 0 {
+		session, err := gDriveStartUpload(fileID, metadata, size)
+		if err != nil {
+			return nil, err
+		}
+		offset = 0
+		gDriveSaveUploadSession(f, session, offset, size, modTime)
+	}
+
+	chunkSize := uploadChunkSize()
+	for {
+		length := size - offset
+		if length > chunkSize {
+			length = chunkSize
+		}
+
+		_, err = localFile.Seek(offset, io.SeekStart)
+		if err != nil {
+			return nil, err
+		}
+
+		file, next, err := gDriveUploadChunk(f.GetUploadSession(), io.LimitReader(throttleUpload(localFile), length), offset, length, size)
+		if err != nil {
+			return nil, err
+		}
+		if file != nil {
+			gDriveSaveUploadSession(f, "", 0, 0, 0)
+			return file, nil
+		}
+		offset = next
+		gDriveSaveUploadSession(f, f.GetUploadSession(), offset, size, modTime)
+	}
+}
+
+// gDriveStartUpload opens a resumable upload session and returns its URI.
+func gDriveStartUpload(fileID string, metadata *drive.File, size int64) (string, error) {
+	body, err := json.Marshal(metadata)
+	if err != nil {
+		return "", err
+	}
+
+	method, target := http.MethodPost, gDriveUploadURL
+	if fileID != "" {
+		method, target = http.MethodPatch, gDriveUploadURL+"/"+fileID
+	}
+	req, err := http.NewRequestWithContext(context.Background(), method,
+		target+"?uploadType=resumable&fields="+url.QueryEscape(gDriveEntryFields), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Set("X-Upload-Content-Length", strconv.FormatInt(size, 10))
+	if metadata.MimeType != "" {
+		req.Header.Set("X-Upload-Content-Type", metadata.MimeType)
+	}
+
+	res, err := gDriveClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer googleapi.CloseBody(res)
+	if err = googleapi.CheckResponse(res); err != nil {
+		return "", err
+	}
+
+	session := res.Header.Get("Location")
+	if session == "" {
+		return "", fmt.Errorf("drive did not return an upload session")
+	}
+	return session, nil
+}
+
+// gDriveQueryUpload asks Drive how much of a session it has received. It
+// returns the finished file when the upload is already complete.
+func gDriveQueryUpload(session string, size int64) (*drive.File, int64, error) {
+	return gDriveUploadChunk(session, http.NoBody, -1, 0, size)
+}
+
+// gDriveUploadChunk sends length bytes starting at offset. A negative offset
+// sends no content and only asks for the session status. It returns either the
+// finished file or the offset the next chunk has to start at.
+func gDriveUploadChunk(session string, content io.Reader, offset, length, size int64) (*drive.File, int64, error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPut, session, content)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.ContentLength = length
+	if offset < 0 || length == 0 {
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+	} else {
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, size))
+	}
+
+	res, err := gDriveClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer googleapi.CloseBody(res)
+
+	if res.StatusCode == http.StatusPermanentRedirect {
+		// "Range: bytes=0-N" confirms everything up to N, no header means
+		// nothing was stored yet.
+		received := res.Header.Get("Range")
+		if received == "" {
+			return nil, 0, nil
+		}
+		last, err := strconv.ParseInt(received[strings.LastIndex(received, "-")+1:], 10, 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid range %q from drive: %w", received, err)
+		}
+		return nil, last + 1, nil
+	}
+	if err = googleapi.CheckResponse(res); err != nil {
+		return nil, 0, err
+	}
+
+	file := new(drive.File)
+	err = json.NewDecoder(res.Body).Decode(file)
+	if err != nil {
+		return nil, 0, err
+	}
+	return file, size, nil
+}
+
+func gDriveSaveUploadSession(f *pb.Node, session string, offset, size, modTime int64) {
+	f.UploadSession = session
+	f.UploadOffset = offset
+	f.UploadSize = size
+	f.UploadModTime = modTime
+	err := database.UpdateNodeFields(f.GetId(), map[string]interface{}{
+		"upload_session":  f.UploadSession,
+		"upload_offset":   f.UploadOffset,
+		"upload_size":     f.UploadSize,
+		"upload_mod_time": f.UploadModTime,
+	})
+	if err != nil {
+		fmt.Printf("Unable to update node: %v", err)
+	}
+}
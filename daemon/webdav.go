@@ -0,0 +1,307 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/Regis-Caelum/drive-sync/config"
+	pb "github.com/Regis-Caelum/drive-sync/proto/generated"
+	"golang.org/x/net/context"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// webdavBackend mirrors the watched trees into a WebDAV share, for instance
+// the files of a Nextcloud or ownCloud account. Entries are addressed by their
+// slash separated path relative to the share URL.
+type webdavBackend struct {
+	base     *url.URL
+	user     string
+	password string
+	client   *http.Client
+}
+
+// webdavPropfind asks for the properties List and Exists need.
+const webdavPropfind = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:">
+  <d:prop><d:resourcetype/><d:getcontentlength/><d:getlastmodified/></d:prop>
+</d:propfind>`
+
+type webdavMultistatus struct {
+	Responses []webdavResponse `xml:"response"`
+}
+
+type webdavResponse struct {
+	Href string `xml:"href"`
+	Prop struct {
+		ResourceType struct {
+			Collection *struct{} `xml:"collection"`
+		} `xml:"resourcetype"`
+		ContentLength int64  `xml:"getcontentlength"`
+		LastModified  string `xml:"getlastmodified"`
+	} `xml:"propstat>prop"`
+}
+
+// newWebdavBackend connects to the share configured by the [backend.webdav]
+// settings.
+func newWebdavBackend() (Backend, error) {
+	settings := config.Current().Backend.WebDAV
+	rawURL := settings.URL
+	if rawURL == "" {
+		return nil, errors.New("url of [backend.webdav] is not set")
+	}
+	base, err := url.Parse(strings.TrimSuffix(rawURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid url of [backend.webdav]: %w", err)
+	}
+
+	return &webdavBackend{
+		base:     base,
+		user:     settings.User,
+		password: settings.Password,
+		client:   &http.Client{},
+	}, nil
+}
+
+func (b *webdavBackend) Name() string {
+	return "webdav"
+}
+
+func (b *webdavBackend) EnsureFolder(name, parentID, localPath string) (string, error) {
+	id := path.Join(parentID, name)
+	res, err := b.do("MKCOL", id, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	res.Body.Close()
+
+	// 405 is the answer for a collection that exists already.
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		err = b.check("MKCOL", id, res)
+		if err != nil {
+			return "", err
+		}
+	}
+	return id, nil
+}
+
+func (b *webdavBackend) CreateFile(name, parentID string, f *pb.Node) (*remoteEntry, error) {
+	return b.UpdateFile(path.Join(parentID, name), f)
+}
+
+func (b *webdavBackend) UpdateFile(id string, f *pb.Node) (*remoteEntry, error) {
+	err := b.putFile(id, f)
+	if err != nil {
+		return nil, err
+	}
+	return b.Stat(id)
+}
+
+func (b *webdavBackend) Move(id, name, localPath, oldParentID, newParentID string) (string, error) {
+	newID := path.Join(newParentID, name)
+	res, err := b.do("MOVE", id, nil, http.Header{
+		"Destination": {b.url(newID)},
+		"Overwrite":   {"T"},
+	})
+	if err != nil {
+		return "", err
+	}
+	res.Body.Close()
+	return newID, b.check("MOVE", id, res)
+}
+
+func (b *webdavBackend) Delete(id string) error {
+	res, err := b.do(http.MethodDelete, id, nil, nil)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return b.check("DELETE", id, res)
+}
+
+func (b *webdavBackend) Exists(id string) (bool, error) {
+	_, err := b.propfind(id, "0")
+	if errors.Is(err, errRemoteNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (b *webdavBackend) Stat(id string) (*remoteEntry, error) {
+	status, err := b.propfind(id, "0")
+	if err != nil {
+		return nil, err
+	}
+	if len(status.Responses) == 0 {
+		return nil, fmt.Errorf("empty PROPFIND response for %s", id)
+	}
+	return b.entry(id, status.Responses[0]), nil
+}
+
+func (b *webdavBackend) Lookup(name, parentID string) (*remoteEntry, error) {
+	entry, err := b.Stat(path.Join(parentID, name))
+	if errors.Is(err, errRemoteNotFound) {
+		return nil, nil
+	}
+	return entry, err
+}
+
+func (b *webdavBackend) Download(id string, offset int64, w io.Writer) error {
+	var header http.Header
+	if offset > 0 {
+		header = http.Header{"Range": {fmt.Sprintf("bytes=%d-", offset)}}
+	}
+	res, err := b.do(http.MethodGet, id, nil, header)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	err = b.check("GET", id, res)
+	if err != nil {
+		return err
+	}
+	return copyFrom(w, res.Body, offset, res.StatusCode == http.StatusPartialContent)
+}
+
+func (b *webdavBackend) List(id string) ([]*remoteEntry, error) {
+	status, err := b.propfind(id, "1")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list %s: %w", id, err)
+	}
+
+	self := strings.TrimSuffix(path.Join(b.base.Path, id), "/")
+	var entries []*remoteEntry
+	for _, r := range status.Responses {
+		u, err := url.Parse(r.Href)
+		if err != nil {
+			continue
+		}
+		href := strings.TrimSuffix(u.Path, "/")
+		if href == self {
+			continue
+		}
+		entries = append(entries, b.entry(path.Join(id, path.Base(href)), r))
+	}
+	return entries, nil
+}
+
+func (b *webdavBackend) entry(id string, r webdavResponse) *remoteEntry {
+	modified, _ := time.Parse(http.TimeFormat, r.Prop.LastModified)
+	return &remoteEntry{
+		ID:           id,
+		Name:         path.Base(id),
+		IsDir:        r.Prop.ResourceType.Collection != nil,
+		Size:         r.Prop.ContentLength,
+		ModifiedTime: modified,
+	}
+}
+
+func (b *webdavBackend) propfind(id, depth string) (*webdavMultistatus, error) {
+	res, err := b.do("PROPFIND", id, strings.NewReader(webdavPropfind), http.Header{
+		"Depth":        {depth},
+		"Content-Type": {"application/xml; charset=utf-8"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	err = b.check("PROPFIND", id, res)
+	if err != nil {
+		return nil, err
+	}
+	status := new(webdavMultistatus)
+	err = xml.NewDecoder(res.Body).Decode(status)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PROPFIND response for %s: %w", id, err)
+	}
+	return status, nil
+}
+
+// putFile uploads the content of the node to the file id.
+func (b *webdavBackend) putFile(id string, f *pb.Node) error {
+	localFile, err := os.Open(f.GetAbsolutePath())
+	if err != nil {
+		return fmt.Errorf("unable to open local file: %w", err)
+	}
+	defer localFile.Close()
+
+	info, err := localFile.Stat()
+	if err != nil {
+		return err
+	}
+
+	req, err := b.request(http.MethodPut, id, throttleUpload(localFile), nil)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+	if info.Size() == 0 {
+		req.Body = http.NoBody
+	}
+
+	res, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return b.check("PUT", id, res)
+}
+
+func (b *webdavBackend) do(method, id string, body io.Reader, header http.Header) (*http.Response, error) {
+	req, err := b.request(method, id, body, header)
+	if err != nil {
+		return nil, err
+	}
+	return b.client.Do(req)
+}
+
+func (b *webdavBackend) request(method, id string, body io.Reader, header http.Header) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(context.Background(), method, b.url(id), body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if b.user != "" {
+		req.SetBasicAuth(b.user, b.password)
+	}
+	return req, nil
+}
+
+// url returns the URL of the entry id.
+func (b *webdavBackend) url(id string) string {
+	u := *b.base
+	u.Path = path.Join(u.Path, id)
+	return u.String()
+}
+
+// check turns an unsuccessful response into an error classified like the
+// ones of the other backends.
+func (b *webdavBackend) check(method, id string, res *http.Response) error {
+	if res.StatusCode < http.StatusMultipleChoices {
+		return nil
+	}
+
+	err := fmt.Errorf("webdav %s %s: %s", method, id, res.Status)
+	switch {
+	case res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusConflict:
+		// 409 means that a parent collection is missing.
+		return fmt.Errorf("%w: %w", errRemoteNotFound, err)
+	case res.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("%w: %w", errUnauthorized, err)
+	case res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusLocked ||
+		res.StatusCode >= http.StatusInternalServerError:
+		return fmt.Errorf("%w: %w", errRetryable, err)
+	case res.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		return fmt.Errorf("%w: %w", errRangeNotSatisfiable, err)
+	}
+	return err
+}
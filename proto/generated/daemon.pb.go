@@ -23,11 +23,13 @@ const (
 type FILE_STATUS int32
 
 const (
-	FILE_STATUS_UNMODIFIED   FILE_STATUS = 0
-	FILE_STATUS_MODIFIED     FILE_STATUS = 1
-	FILE_STATUS_UPLOADED     FILE_STATUS = 3
-	FILE_STATUS_NOT_UPLOADED FILE_STATUS = 4
-	FILE_STATUS_UNTRACKED    FILE_STATUS = 5
+	FILE_STATUS_UNMODIFIED    FILE_STATUS = 0
+	FILE_STATUS_MODIFIED      FILE_STATUS = 1
+	FILE_STATUS_UPLOADED      FILE_STATUS = 3
+	FILE_STATUS_NOT_UPLOADED  FILE_STATUS = 4
+	FILE_STATUS_UNTRACKED     FILE_STATUS = 5
+	FILE_STATUS_UPLOAD_FAILED FILE_STATUS = 6
+	FILE_STATUS_CONFLICT      FILE_STATUS = 7
 )
 
 // Enum value maps for FILE_STATUS.
@@ -38,13 +40,17 @@ var (
 		3: "UPLOADED",
 		4: "NOT_UPLOADED",
 		5: "UNTRACKED",
+		6: "UPLOAD_FAILED",
+		7: "CONFLICT",
 	}
 	FILE_STATUS_value = map[string]int32{
-		"UNMODIFIED":   0,
-		"MODIFIED":     1,
-		"UPLOADED":     3,
-		"NOT_UPLOADED": 4,
-		"UNTRACKED":    5,
+		"UNMODIFIED":    0,
+		"MODIFIED":      1,
+		"UPLOADED":      3,
+		"NOT_UPLOADED":  4,
+		"UNTRACKED":     5,
+		"UPLOAD_FAILED": 6,
+		"CONFLICT":      7,
 	}
 )
 
@@ -176,18 +182,339 @@ func (ADD_DIRECTORY_STATUS) EnumDescriptor() ([]byte, []int) {
 	return file_daemon_proto_rawDescGZIP(), []int{2}
 }
 
+type HIDDEN_POLICY int32
+
+const (
+	HIDDEN_POLICY_EXCLUDE_HIDDEN_DIRS HIDDEN_POLICY = 0
+	HIDDEN_POLICY_INCLUDE_HIDDEN      HIDDEN_POLICY = 1
+	HIDDEN_POLICY_EXCLUDE_HIDDEN      HIDDEN_POLICY = 2
+)
+
+// Enum value maps for HIDDEN_POLICY.
+var (
+	HIDDEN_POLICY_name = map[int32]string{
+		0: "EXCLUDE_HIDDEN_DIRS",
+		1: "INCLUDE_HIDDEN",
+		2: "EXCLUDE_HIDDEN",
+	}
+	HIDDEN_POLICY_value = map[string]int32{
+		"EXCLUDE_HIDDEN_DIRS": 0,
+		"INCLUDE_HIDDEN":      1,
+		"EXCLUDE_HIDDEN":      2,
+	}
+)
+
+func (x HIDDEN_POLICY) Enum() *HIDDEN_POLICY {
+	p := new(HIDDEN_POLICY)
+	*p = x
+	return p
+}
+
+func (x HIDDEN_POLICY) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (HIDDEN_POLICY) Descriptor() protoreflect.EnumDescriptor {
+	return file_daemon_proto_enumTypes[3].Descriptor()
+}
+
+func (HIDDEN_POLICY) Type() protoreflect.EnumType {
+	return &file_daemon_proto_enumTypes[3]
+}
+
+func (x HIDDEN_POLICY) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use HIDDEN_POLICY.Descriptor instead.
+func (HIDDEN_POLICY) EnumDescriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{3}
+}
+
+// LOGOUT_MODE says what happens to the remote state of the account logged
+// out of.
+type LOGOUT_MODE int32
+
+const (
+	LOGOUT_MODE_KEEP_STATE        LOGOUT_MODE = 0
+	LOGOUT_MODE_FORGET_REMOTE_IDS LOGOUT_MODE = 1
+	LOGOUT_MODE_PURGE_REMOTE      LOGOUT_MODE = 2
+)
+
+// Enum value maps for LOGOUT_MODE.
+var (
+	LOGOUT_MODE_name = map[int32]string{
+		0: "KEEP_STATE",
+		1: "FORGET_REMOTE_IDS",
+		2: "PURGE_REMOTE",
+	}
+	LOGOUT_MODE_value = map[string]int32{
+		"KEEP_STATE":        0,
+		"FORGET_REMOTE_IDS": 1,
+		"PURGE_REMOTE":      2,
+	}
+)
+
+func (x LOGOUT_MODE) Enum() *LOGOUT_MODE {
+	p := new(LOGOUT_MODE)
+	*p = x
+	return p
+}
+
+func (x LOGOUT_MODE) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LOGOUT_MODE) Descriptor() protoreflect.EnumDescriptor {
+	return file_daemon_proto_enumTypes[4].Descriptor()
+}
+
+func (LOGOUT_MODE) Type() protoreflect.EnumType {
+	return &file_daemon_proto_enumTypes[4]
+}
+
+func (x LOGOUT_MODE) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LOGOUT_MODE.Descriptor instead.
+func (LOGOUT_MODE) EnumDescriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{4}
+}
+
+type JOB_ACTION int32
+
+const (
+	JOB_ACTION_SYNC_FILE        JOB_ACTION = 0
+	JOB_ACTION_SYNC_FOLDER      JOB_ACTION = 1
+	JOB_ACTION_DELETE_REMOTE    JOB_ACTION = 2
+	JOB_ACTION_MOVE_REMOTE      JOB_ACTION = 3
+	JOB_ACTION_RESOLVE_CONFLICT JOB_ACTION = 4
+)
+
+// Enum value maps for JOB_ACTION.
+var (
+	JOB_ACTION_name = map[int32]string{
+		0: "SYNC_FILE",
+		1: "SYNC_FOLDER",
+		2: "DELETE_REMOTE",
+		3: "MOVE_REMOTE",
+		4: "RESOLVE_CONFLICT",
+	}
+	JOB_ACTION_value = map[string]int32{
+		"SYNC_FILE":        0,
+		"SYNC_FOLDER":      1,
+		"DELETE_REMOTE":    2,
+		"MOVE_REMOTE":      3,
+		"RESOLVE_CONFLICT": 4,
+	}
+)
+
+func (x JOB_ACTION) Enum() *JOB_ACTION {
+	p := new(JOB_ACTION)
+	*p = x
+	return p
+}
+
+func (x JOB_ACTION) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JOB_ACTION) Descriptor() protoreflect.EnumDescriptor {
+	return file_daemon_proto_enumTypes[5].Descriptor()
+}
+
+func (JOB_ACTION) Type() protoreflect.EnumType {
+	return &file_daemon_proto_enumTypes[5]
+}
+
+func (x JOB_ACTION) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JOB_ACTION.Descriptor instead.
+func (JOB_ACTION) EnumDescriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{5}
+}
+
+type CONFLICT_POLICY int32
+
+const (
+	CONFLICT_POLICY_KEEP_BOTH   CONFLICT_POLICY = 0
+	CONFLICT_POLICY_LOCAL_WINS  CONFLICT_POLICY = 1
+	CONFLICT_POLICY_REMOTE_WINS CONFLICT_POLICY = 2
+	CONFLICT_POLICY_MANUAL      CONFLICT_POLICY = 3
+)
+
+// Enum value maps for CONFLICT_POLICY.
+var (
+	CONFLICT_POLICY_name = map[int32]string{
+		0: "KEEP_BOTH",
+		1: "LOCAL_WINS",
+		2: "REMOTE_WINS",
+		3: "MANUAL",
+	}
+	CONFLICT_POLICY_value = map[string]int32{
+		"KEEP_BOTH":   0,
+		"LOCAL_WINS":  1,
+		"REMOTE_WINS": 2,
+		"MANUAL":      3,
+	}
+)
+
+func (x CONFLICT_POLICY) Enum() *CONFLICT_POLICY {
+	p := new(CONFLICT_POLICY)
+	*p = x
+	return p
+}
+
+func (x CONFLICT_POLICY) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CONFLICT_POLICY) Descriptor() protoreflect.EnumDescriptor {
+	return file_daemon_proto_enumTypes[6].Descriptor()
+}
+
+func (CONFLICT_POLICY) Type() protoreflect.EnumType {
+	return &file_daemon_proto_enumTypes[6]
+}
+
+func (x CONFLICT_POLICY) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CONFLICT_POLICY.Descriptor instead.
+func (CONFLICT_POLICY) EnumDescriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{6}
+}
+
+type JOB_STATUS int32
+
+const (
+	JOB_STATUS_PENDING JOB_STATUS = 0
+	JOB_STATUS_RUNNING JOB_STATUS = 1
+)
+
+// Enum value maps for JOB_STATUS.
+var (
+	JOB_STATUS_name = map[int32]string{
+		0: "PENDING",
+		1: "RUNNING",
+	}
+	JOB_STATUS_value = map[string]int32{
+		"PENDING": 0,
+		"RUNNING": 1,
+	}
+)
+
+func (x JOB_STATUS) Enum() *JOB_STATUS {
+	p := new(JOB_STATUS)
+	*p = x
+	return p
+}
+
+func (x JOB_STATUS) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JOB_STATUS) Descriptor() protoreflect.EnumDescriptor {
+	return file_daemon_proto_enumTypes[7].Descriptor()
+}
+
+func (JOB_STATUS) Type() protoreflect.EnumType {
+	return &file_daemon_proto_enumTypes[7]
+}
+
+func (x JOB_STATUS) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JOB_STATUS.Descriptor instead.
+func (JOB_STATUS) EnumDescriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{7}
+}
+
+type PULL_STATUS int32
+
+const (
+	PULL_STATUS_DOWNLOADING      PULL_STATUS = 0
+	PULL_STATUS_DOWNLOADED       PULL_STATUS = 1
+	PULL_STATUS_UP_TO_DATE       PULL_STATUS = 2
+	PULL_STATUS_WOULD_DOWNLOAD   PULL_STATUS = 3
+	PULL_STATUS_DOWNLOAD_SKIPPED PULL_STATUS = 4
+	PULL_STATUS_DOWNLOAD_FAILED  PULL_STATUS = 5
+)
+
+// Enum value maps for PULL_STATUS.
+var (
+	PULL_STATUS_name = map[int32]string{
+		0: "DOWNLOADING",
+		1: "DOWNLOADED",
+		2: "UP_TO_DATE",
+		3: "WOULD_DOWNLOAD",
+		4: "DOWNLOAD_SKIPPED",
+		5: "DOWNLOAD_FAILED",
+	}
+	PULL_STATUS_value = map[string]int32{
+		"DOWNLOADING":      0,
+		"DOWNLOADED":       1,
+		"UP_TO_DATE":       2,
+		"WOULD_DOWNLOAD":   3,
+		"DOWNLOAD_SKIPPED": 4,
+		"DOWNLOAD_FAILED":  5,
+	}
+)
+
+func (x PULL_STATUS) Enum() *PULL_STATUS {
+	p := new(PULL_STATUS)
+	*p = x
+	return p
+}
+
+func (x PULL_STATUS) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PULL_STATUS) Descriptor() protoreflect.EnumDescriptor {
+	return file_daemon_proto_enumTypes[8].Descriptor()
+}
+
+func (PULL_STATUS) Type() protoreflect.EnumType {
+	return &file_daemon_proto_enumTypes[8]
+}
+
+func (x PULL_STATUS) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PULL_STATUS.Descriptor instead.
+func (PULL_STATUS) EnumDescriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{8}
+}
+
 type Node struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id           int32       `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name         string      `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	IsDir        bool        `protobuf:"varint,3,opt,name=is_dir,json=isDir,proto3" json:"is_dir,omitempty"`
-	FileStatus   FILE_STATUS `protobuf:"varint,4,opt,name=file_status,json=fileStatus,proto3,enum=generated.FILE_STATUS" json:"file_status,omitempty"`
-	UploadStatus FILE_STATUS `protobuf:"varint,5,opt,name=upload_status,json=uploadStatus,proto3,enum=generated.FILE_STATUS" json:"upload_status,omitempty"`
-	AbsolutePath string      `protobuf:"bytes,6,opt,name=absolute_path,json=absolutePath,proto3" json:"absolute_path,omitempty"`
-	DriveId      string      `protobuf:"bytes,7,opt,name=drive_id,json=driveId,proto3" json:"drive_id,omitempty"`
+	Id                 int32       `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name               string      `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	IsDir              bool        `protobuf:"varint,3,opt,name=is_dir,json=isDir,proto3" json:"is_dir,omitempty"`
+	FileStatus         FILE_STATUS `protobuf:"varint,4,opt,name=file_status,json=fileStatus,proto3,enum=generated.FILE_STATUS" json:"file_status,omitempty"`
+	UploadStatus       FILE_STATUS `protobuf:"varint,5,opt,name=upload_status,json=uploadStatus,proto3,enum=generated.FILE_STATUS" json:"upload_status,omitempty"`
+	AbsolutePath       string      `protobuf:"bytes,6,opt,name=absolute_path,json=absolutePath,proto3" json:"absolute_path,omitempty"`
+	DriveId            string      `protobuf:"bytes,7,opt,name=drive_id,json=driveId,proto3" json:"drive_id,omitempty"`
+	UploadSession      string      `protobuf:"bytes,8,opt,name=upload_session,json=uploadSession,proto3" json:"upload_session,omitempty"`
+	UploadOffset       int64       `protobuf:"varint,9,opt,name=upload_offset,json=uploadOffset,proto3" json:"upload_offset,omitempty"`
+	UploadSize         int64       `protobuf:"varint,10,opt,name=upload_size,json=uploadSize,proto3" json:"upload_size,omitempty"`
+	UploadModTime      int64       `protobuf:"varint,11,opt,name=upload_mod_time,json=uploadModTime,proto3" json:"upload_mod_time,omitempty"`
+	Error              string      `protobuf:"bytes,12,opt,name=error,proto3" json:"error,omitempty"`
+	RemoteMd5          string      `protobuf:"bytes,13,opt,name=remote_md5,json=remoteMd5,proto3" json:"remote_md5,omitempty"`
+	RemoteModifiedTime string      `protobuf:"bytes,14,opt,name=remote_modified_time,json=remoteModifiedTime,proto3" json:"remote_modified_time,omitempty"`
+	Inode              uint64      `protobuf:"varint,15,opt,name=inode,proto3" json:"inode,omitempty"`
+	Size               int64       `protobuf:"varint,16,opt,name=size,proto3" json:"size,omitempty"`
+	ModTime            int64       `protobuf:"varint,17,opt,name=mod_time,json=modTime,proto3" json:"mod_time,omitempty"`
 }
 
 func (x *Node) Reset() {
@@ -271,15 +598,87 @@ func (x *Node) GetDriveId() string {
 	return ""
 }
 
+func (x *Node) GetUploadSession() string {
+	if x != nil {
+		return x.UploadSession
+	}
+	return ""
+}
+
+func (x *Node) GetUploadOffset() int64 {
+	if x != nil {
+		return x.UploadOffset
+	}
+	return 0
+}
+
+func (x *Node) GetUploadSize() int64 {
+	if x != nil {
+		return x.UploadSize
+	}
+	return 0
+}
+
+func (x *Node) GetUploadModTime() int64 {
+	if x != nil {
+		return x.UploadModTime
+	}
+	return 0
+}
+
+func (x *Node) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Node) GetRemoteMd5() string {
+	if x != nil {
+		return x.RemoteMd5
+	}
+	return ""
+}
+
+func (x *Node) GetRemoteModifiedTime() string {
+	if x != nil {
+		return x.RemoteModifiedTime
+	}
+	return ""
+}
+
+func (x *Node) GetInode() uint64 {
+	if x != nil {
+		return x.Inode
+	}
+	return 0
+}
+
+func (x *Node) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Node) GetModTime() int64 {
+	if x != nil {
+		return x.ModTime
+	}
+	return 0
+}
+
 type WatchList struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id           int32  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name         string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	AbsolutePath string `protobuf:"bytes,3,opt,name=absolute_path,json=absolutePath,proto3" json:"absolute_path,omitempty"`
-	DriveId      string `protobuf:"bytes,4,opt,name=drive_id,json=driveId,proto3" json:"drive_id,omitempty"`
+	Id           int32         `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name         string        `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	AbsolutePath string        `protobuf:"bytes,3,opt,name=absolute_path,json=absolutePath,proto3" json:"absolute_path,omitempty"`
+	DriveId      string        `protobuf:"bytes,4,opt,name=drive_id,json=driveId,proto3" json:"drive_id,omitempty"`
+	HiddenPolicy HIDDEN_POLICY `protobuf:"varint,5,opt,name=hidden_policy,json=hiddenPolicy,proto3,enum=generated.HIDDEN_POLICY" json:"hidden_policy,omitempty"`
+	Inode        uint64        `protobuf:"varint,6,opt,name=inode,proto3" json:"inode,omitempty"`
 }
 
 func (x *WatchList) Reset() {
@@ -342,15 +741,38 @@ func (x *WatchList) GetDriveId() string {
 	return ""
 }
 
+func (x *WatchList) GetHiddenPolicy() HIDDEN_POLICY {
+	if x != nil {
+		return x.HiddenPolicy
+	}
+	return HIDDEN_POLICY_EXCLUDE_HIDDEN_DIRS
+}
+
+func (x *WatchList) GetInode() uint64 {
+	if x != nil {
+		return x.Inode
+	}
+	return 0
+}
+
 type OAuth2Token struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id    int32  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Root  string `protobuf:"bytes,2,opt,name=root,proto3" json:"root,omitempty"`
-	Host  string `protobuf:"bytes,3,opt,name=host,proto3" json:"host,omitempty"`
-	Value string `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	Id           int32  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Root         string `protobuf:"bytes,2,opt,name=root,proto3" json:"root,omitempty"`
+	Host         string `protobuf:"bytes,3,opt,name=host,proto3" json:"host,omitempty"`
+	Value        string `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	Backend      string `protobuf:"bytes,5,opt,name=backend,proto3" json:"backend,omitempty"`
+	ChangesToken string `protobuf:"bytes,6,opt,name=changes_token,json=changesToken,proto3" json:"changes_token,omitempty"`
+	// client is the client secret JSON given with `dsync login --client-secret`,
+	// empty for the built-in client. subject is the user a service account
+	// acts as through domain-wide delegation.
+	Client  string `protobuf:"bytes,7,opt,name=client,proto3" json:"client,omitempty"`
+	Subject string `protobuf:"bytes,8,opt,name=subject,proto3" json:"subject,omitempty"`
+	// account identifies the account the remote IDs belong to.
+	Account string `protobuf:"bytes,9,opt,name=account,proto3" json:"account,omitempty"`
 }
 
 func (x *OAuth2Token) Reset() {
@@ -413,20 +835,51 @@ func (x *OAuth2Token) GetValue() string {
 	return ""
 }
 
-type DriveRecord struct {
+func (x *OAuth2Token) GetBackend() string {
+	if x != nil {
+		return x.Backend
+	}
+	return ""
+}
+
+func (x *OAuth2Token) GetChangesToken() string {
+	if x != nil {
+		return x.ChangesToken
+	}
+	return ""
+}
+
+func (x *OAuth2Token) GetClient() string {
+	if x != nil {
+		return x.Client
+	}
+	return ""
+}
+
+func (x *OAuth2Token) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *OAuth2Token) GetAccount() string {
+	if x != nil {
+		return x.Account
+	}
+	return ""
+}
+
+type LogoutRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id        int32  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name      string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	LocalPath string `protobuf:"bytes,3,opt,name=local_path,json=localPath,proto3" json:"local_path,omitempty"`
-	DriveId   string `protobuf:"bytes,4,opt,name=drive_id,json=driveId,proto3" json:"drive_id,omitempty"`
-	ParentId  string `protobuf:"bytes,5,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	Mode LOGOUT_MODE `protobuf:"varint,1,opt,name=mode,proto3,enum=generated.LOGOUT_MODE" json:"mode,omitempty"`
 }
 
-func (x *DriveRecord) Reset() {
-	*x = DriveRecord{}
+func (x *LogoutRequest) Reset() {
+	*x = LogoutRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_daemon_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -434,13 +887,13 @@ func (x *DriveRecord) Reset() {
 	}
 }
 
-func (x *DriveRecord) String() string {
+func (x *LogoutRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DriveRecord) ProtoMessage() {}
+func (*LogoutRequest) ProtoMessage() {}
 
-func (x *DriveRecord) ProtoReflect() protoreflect.Message {
+func (x *LogoutRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_daemon_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -452,11 +905,244 @@ func (x *DriveRecord) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DriveRecord.ProtoReflect.Descriptor instead.
-func (*DriveRecord) Descriptor() ([]byte, []int) {
+// Deprecated: Use LogoutRequest.ProtoReflect.Descriptor instead.
+func (*LogoutRequest) Descriptor() ([]byte, []int) {
 	return file_daemon_proto_rawDescGZIP(), []int{3}
 }
 
+func (x *LogoutRequest) GetMode() LOGOUT_MODE {
+	if x != nil {
+		return x.Mode
+	}
+	return LOGOUT_MODE_KEEP_STATE
+}
+
+type OAuthClient struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Config string `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+}
+
+func (x *OAuthClient) Reset() {
+	*x = OAuthClient{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OAuthClient) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OAuthClient) ProtoMessage() {}
+
+func (x *OAuthClient) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OAuthClient.ProtoReflect.Descriptor instead.
+func (*OAuthClient) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *OAuthClient) GetConfig() string {
+	if x != nil {
+		return x.Config
+	}
+	return ""
+}
+
+type AuthStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LoggedIn       bool     `protobuf:"varint,1,opt,name=logged_in,json=loggedIn,proto3" json:"logged_in,omitempty"`
+	Connected      bool     `protobuf:"varint,2,opt,name=connected,proto3" json:"connected,omitempty"`
+	Backend        string   `protobuf:"bytes,3,opt,name=backend,proto3" json:"backend,omitempty"`
+	Email          string   `protobuf:"bytes,4,opt,name=email,proto3" json:"email,omitempty"`
+	Scopes         []string `protobuf:"bytes,5,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	Expiry         int64    `protobuf:"varint,6,opt,name=expiry,proto3" json:"expiry,omitempty"`
+	RootFolderId   string   `protobuf:"bytes,7,opt,name=root_folder_id,json=rootFolderId,proto3" json:"root_folder_id,omitempty"`
+	HostFolderId   string   `protobuf:"bytes,8,opt,name=host_folder_id,json=hostFolderId,proto3" json:"host_folder_id,omitempty"`
+	Error          string   `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	ClientId       string   `protobuf:"bytes,10,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	ServiceAccount bool     `protobuf:"varint,11,opt,name=service_account,json=serviceAccount,proto3" json:"service_account,omitempty"`
+	Subject        string   `protobuf:"bytes,12,opt,name=subject,proto3" json:"subject,omitempty"`
+}
+
+func (x *AuthStatus) Reset() {
+	*x = AuthStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AuthStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthStatus) ProtoMessage() {}
+
+func (x *AuthStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthStatus.ProtoReflect.Descriptor instead.
+func (*AuthStatus) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AuthStatus) GetLoggedIn() bool {
+	if x != nil {
+		return x.LoggedIn
+	}
+	return false
+}
+
+func (x *AuthStatus) GetConnected() bool {
+	if x != nil {
+		return x.Connected
+	}
+	return false
+}
+
+func (x *AuthStatus) GetBackend() string {
+	if x != nil {
+		return x.Backend
+	}
+	return ""
+}
+
+func (x *AuthStatus) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *AuthStatus) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+func (x *AuthStatus) GetExpiry() int64 {
+	if x != nil {
+		return x.Expiry
+	}
+	return 0
+}
+
+func (x *AuthStatus) GetRootFolderId() string {
+	if x != nil {
+		return x.RootFolderId
+	}
+	return ""
+}
+
+func (x *AuthStatus) GetHostFolderId() string {
+	if x != nil {
+		return x.HostFolderId
+	}
+	return ""
+}
+
+func (x *AuthStatus) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *AuthStatus) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *AuthStatus) GetServiceAccount() bool {
+	if x != nil {
+		return x.ServiceAccount
+	}
+	return false
+}
+
+func (x *AuthStatus) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+type DriveRecord struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id        int32  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name      string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	LocalPath string `protobuf:"bytes,3,opt,name=local_path,json=localPath,proto3" json:"local_path,omitempty"`
+	DriveId   string `protobuf:"bytes,4,opt,name=drive_id,json=driveId,proto3" json:"drive_id,omitempty"`
+	ParentId  string `protobuf:"bytes,5,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+}
+
+func (x *DriveRecord) Reset() {
+	*x = DriveRecord{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DriveRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DriveRecord) ProtoMessage() {}
+
+func (x *DriveRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DriveRecord.ProtoReflect.Descriptor instead.
+func (*DriveRecord) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{6}
+}
+
 func (x *DriveRecord) GetId() int32 {
 	if x != nil {
 		return x.Id
@@ -468,55 +1154,666 @@ func (x *DriveRecord) GetName() string {
 	if x != nil {
 		return x.Name
 	}
-	return ""
+	return ""
+}
+
+func (x *DriveRecord) GetLocalPath() string {
+	if x != nil {
+		return x.LocalPath
+	}
+	return ""
+}
+
+func (x *DriveRecord) GetDriveId() string {
+	if x != nil {
+		return x.DriveId
+	}
+	return ""
+}
+
+func (x *DriveRecord) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+type Job struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id        int32           `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Action    JOB_ACTION      `protobuf:"varint,2,opt,name=action,proto3,enum=generated.JOB_ACTION" json:"action,omitempty"`
+	Status    JOB_STATUS      `protobuf:"varint,3,opt,name=status,proto3,enum=generated.JOB_STATUS" json:"status,omitempty"`
+	Path      string          `protobuf:"bytes,4,opt,name=path,proto3" json:"path,omitempty"`
+	OldPath   string          `protobuf:"bytes,5,opt,name=old_path,json=oldPath,proto3" json:"old_path,omitempty"`
+	DriveId   string          `protobuf:"bytes,6,opt,name=drive_id,json=driveId,proto3" json:"drive_id,omitempty"`
+	ParentId  string          `protobuf:"bytes,7,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	Attempts  int32           `protobuf:"varint,8,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	Error     string          `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	NotBefore int64           `protobuf:"varint,10,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"`
+	Policy    CONFLICT_POLICY `protobuf:"varint,11,opt,name=policy,proto3,enum=generated.CONFLICT_POLICY" json:"policy,omitempty"`
+}
+
+func (x *Job) Reset() {
+	*x = Job{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Job) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job) ProtoMessage() {}
+
+func (x *Job) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job.ProtoReflect.Descriptor instead.
+func (*Job) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Job) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Job) GetAction() JOB_ACTION {
+	if x != nil {
+		return x.Action
+	}
+	return JOB_ACTION_SYNC_FILE
+}
+
+func (x *Job) GetStatus() JOB_STATUS {
+	if x != nil {
+		return x.Status
+	}
+	return JOB_STATUS_PENDING
+}
+
+func (x *Job) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Job) GetOldPath() string {
+	if x != nil {
+		return x.OldPath
+	}
+	return ""
+}
+
+func (x *Job) GetDriveId() string {
+	if x != nil {
+		return x.DriveId
+	}
+	return ""
+}
+
+func (x *Job) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+func (x *Job) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *Job) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Job) GetNotBefore() int64 {
+	if x != nil {
+		return x.NotBefore
+	}
+	return 0
+}
+
+func (x *Job) GetPolicy() CONFLICT_POLICY {
+	if x != nil {
+		return x.Policy
+	}
+	return CONFLICT_POLICY_KEEP_BOTH
+}
+
+type PathList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Values       []string       `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	HiddenPolicy *HIDDEN_POLICY `protobuf:"varint,2,opt,name=hidden_policy,json=hiddenPolicy,proto3,enum=generated.HIDDEN_POLICY,oneof" json:"hidden_policy,omitempty"`
+}
+
+func (x *PathList) Reset() {
+	*x = PathList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PathList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PathList) ProtoMessage() {}
+
+func (x *PathList) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PathList.ProtoReflect.Descriptor instead.
+func (*PathList) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PathList) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+func (x *PathList) GetHiddenPolicy() HIDDEN_POLICY {
+	if x != nil && x.HiddenPolicy != nil {
+		return *x.HiddenPolicy
+	}
+	return HIDDEN_POLICY_EXCLUDE_HIDDEN_DIRS
+}
+
+type FileList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DirectoryList []*WatchList `protobuf:"bytes,1,rep,name=directoryList,proto3" json:"directoryList,omitempty"`
+	FileList      []*Node      `protobuf:"bytes,2,rep,name=fileList,proto3" json:"fileList,omitempty"`
+}
+
+func (x *FileList) Reset() {
+	*x = FileList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FileList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileList) ProtoMessage() {}
+
+func (x *FileList) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileList.ProtoReflect.Descriptor instead.
+func (*FileList) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *FileList) GetDirectoryList() []*WatchList {
+	if x != nil {
+		return x.DirectoryList
+	}
+	return nil
+}
+
+func (x *FileList) GetFileList() []*Node {
+	if x != nil {
+		return x.FileList
+	}
+	return nil
+}
+
+type AddDirectoryResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Status ADD_DIRECTORY_STATUS `protobuf:"varint,1,opt,name=status,proto3,enum=generated.ADD_DIRECTORY_STATUS" json:"status,omitempty"`
+	Error  string               `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Path   string               `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+}
+
+func (x *AddDirectoryResponse) Reset() {
+	*x = AddDirectoryResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddDirectoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddDirectoryResponse) ProtoMessage() {}
+
+func (x *AddDirectoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddDirectoryResponse.ProtoReflect.Descriptor instead.
+func (*AddDirectoryResponse) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AddDirectoryResponse) GetStatus() ADD_DIRECTORY_STATUS {
+	if x != nil {
+		return x.Status
+	}
+	return ADD_DIRECTORY_STATUS_PARTIAL
+}
+
+func (x *AddDirectoryResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *AddDirectoryResponse) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type ResponseList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Values []*AddDirectoryResponse `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+}
+
+func (x *ResponseList) Reset() {
+	*x = ResponseList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResponseList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResponseList) ProtoMessage() {}
+
+func (x *ResponseList) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResponseList.ProtoReflect.Descriptor instead.
+func (*ResponseList) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ResponseList) GetValues() []*AddDirectoryResponse {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type ConflictResolution struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path   string          `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Policy CONFLICT_POLICY `protobuf:"varint,2,opt,name=policy,proto3,enum=generated.CONFLICT_POLICY" json:"policy,omitempty"`
+}
+
+func (x *ConflictResolution) Reset() {
+	*x = ConflictResolution{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConflictResolution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConflictResolution) ProtoMessage() {}
+
+func (x *ConflictResolution) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConflictResolution.ProtoReflect.Descriptor instead.
+func (*ConflictResolution) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ConflictResolution) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ConflictResolution) GetPolicy() CONFLICT_POLICY {
+	if x != nil {
+		return x.Policy
+	}
+	return CONFLICT_POLICY_KEEP_BOTH
+}
+
+type PullRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path   string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	To     string `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	DryRun bool   `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	Host   string `protobuf:"bytes,4,opt,name=host,proto3" json:"host,omitempty"`
+	At     int64  `protobuf:"varint,5,opt,name=at,proto3" json:"at,omitempty"`
+}
+
+func (x *PullRequest) Reset() {
+	*x = PullRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PullRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PullRequest) ProtoMessage() {}
+
+func (x *PullRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PullRequest.ProtoReflect.Descriptor instead.
+func (*PullRequest) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *PullRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *PullRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *PullRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *PullRequest) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *PullRequest) GetAt() int64 {
+	if x != nil {
+		return x.At
+	}
+	return 0
+}
+
+type PullProgress struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path   string      `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Status PULL_STATUS `protobuf:"varint,2,opt,name=status,proto3,enum=generated.PULL_STATUS" json:"status,omitempty"`
+	Size   int64       `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Offset int64       `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	Error  string      `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *PullProgress) Reset() {
+	*x = PullProgress{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PullProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PullProgress) ProtoMessage() {}
+
+func (x *PullProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PullProgress.ProtoReflect.Descriptor instead.
+func (*PullProgress) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *PullProgress) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *PullProgress) GetStatus() PULL_STATUS {
+	if x != nil {
+		return x.Status
+	}
+	return PULL_STATUS_DOWNLOADING
+}
+
+func (x *PullProgress) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *PullProgress) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *PullProgress) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type RevisionRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+}
+
+func (x *RevisionRequest) Reset() {
+	*x = RevisionRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_daemon_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
 
-func (x *DriveRecord) GetLocalPath() string {
-	if x != nil {
-		return x.LocalPath
-	}
-	return ""
+func (x *RevisionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *DriveRecord) GetDriveId() string {
-	if x != nil {
-		return x.DriveId
+func (*RevisionRequest) ProtoMessage() {}
+
+func (x *RevisionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *DriveRecord) GetParentId() string {
+// Deprecated: Use RevisionRequest.ProtoReflect.Descriptor instead.
+func (*RevisionRequest) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RevisionRequest) GetPath() string {
 	if x != nil {
-		return x.ParentId
+		return x.Path
 	}
 	return ""
 }
 
-type PathList struct {
+type Revision struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Values []string `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	Id           string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ModifiedTime int64  `protobuf:"varint,2,opt,name=modified_time,json=modifiedTime,proto3" json:"modified_time,omitempty"`
+	Size         int64  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Md5          string `protobuf:"bytes,4,opt,name=md5,proto3" json:"md5,omitempty"`
+	KeepForever  bool   `protobuf:"varint,5,opt,name=keep_forever,json=keepForever,proto3" json:"keep_forever,omitempty"`
 }
 
-func (x *PathList) Reset() {
-	*x = PathList{}
+func (x *Revision) Reset() {
+	*x = Revision{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_daemon_proto_msgTypes[4]
+		mi := &file_daemon_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *PathList) String() string {
+func (x *Revision) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PathList) ProtoMessage() {}
+func (*Revision) ProtoMessage() {}
 
-func (x *PathList) ProtoReflect() protoreflect.Message {
-	mi := &file_daemon_proto_msgTypes[4]
+func (x *Revision) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -527,44 +1824,72 @@ func (x *PathList) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PathList.ProtoReflect.Descriptor instead.
-func (*PathList) Descriptor() ([]byte, []int) {
-	return file_daemon_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use Revision.ProtoReflect.Descriptor instead.
+func (*Revision) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *PathList) GetValues() []string {
+func (x *Revision) GetId() string {
 	if x != nil {
-		return x.Values
+		return x.Id
 	}
-	return nil
+	return ""
 }
 
-type FileList struct {
+func (x *Revision) GetModifiedTime() int64 {
+	if x != nil {
+		return x.ModifiedTime
+	}
+	return 0
+}
+
+func (x *Revision) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Revision) GetMd5() string {
+	if x != nil {
+		return x.Md5
+	}
+	return ""
+}
+
+func (x *Revision) GetKeepForever() bool {
+	if x != nil {
+		return x.KeepForever
+	}
+	return false
+}
+
+type RevisionList struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	DirectoryList []*WatchList `protobuf:"bytes,1,rep,name=directoryList,proto3" json:"directoryList,omitempty"`
-	FileList      []*Node      `protobuf:"bytes,2,rep,name=fileList,proto3" json:"fileList,omitempty"`
+	Path      string      `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Revisions []*Revision `protobuf:"bytes,2,rep,name=revisions,proto3" json:"revisions,omitempty"`
 }
 
-func (x *FileList) Reset() {
-	*x = FileList{}
+func (x *RevisionList) Reset() {
+	*x = RevisionList{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_daemon_proto_msgTypes[5]
+		mi := &file_daemon_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *FileList) String() string {
+func (x *RevisionList) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FileList) ProtoMessage() {}
+func (*RevisionList) ProtoMessage() {}
 
-func (x *FileList) ProtoReflect() protoreflect.Message {
-	mi := &file_daemon_proto_msgTypes[5]
+func (x *RevisionList) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -575,52 +1900,51 @@ func (x *FileList) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FileList.ProtoReflect.Descriptor instead.
-func (*FileList) Descriptor() ([]byte, []int) {
-	return file_daemon_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use RevisionList.ProtoReflect.Descriptor instead.
+func (*RevisionList) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{17}
 }
 
-func (x *FileList) GetDirectoryList() []*WatchList {
+func (x *RevisionList) GetPath() string {
 	if x != nil {
-		return x.DirectoryList
+		return x.Path
 	}
-	return nil
+	return ""
 }
 
-func (x *FileList) GetFileList() []*Node {
+func (x *RevisionList) GetRevisions() []*Revision {
 	if x != nil {
-		return x.FileList
+		return x.Revisions
 	}
 	return nil
 }
 
-type AddDirectoryResponse struct {
+type Host struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Status ADD_DIRECTORY_STATUS `protobuf:"varint,1,opt,name=status,proto3,enum=generated.ADD_DIRECTORY_STATUS" json:"status,omitempty"`
-	Error  string               `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
-	Path   string               `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	Name    string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Current bool   `protobuf:"varint,2,opt,name=current,proto3" json:"current,omitempty"`
 }
 
-func (x *AddDirectoryResponse) Reset() {
-	*x = AddDirectoryResponse{}
+func (x *Host) Reset() {
+	*x = Host{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_daemon_proto_msgTypes[6]
+		mi := &file_daemon_proto_msgTypes[18]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *AddDirectoryResponse) String() string {
+func (x *Host) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AddDirectoryResponse) ProtoMessage() {}
+func (*Host) ProtoMessage() {}
 
-func (x *AddDirectoryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_daemon_proto_msgTypes[6]
+func (x *Host) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[18]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -631,57 +1955,50 @@ func (x *AddDirectoryResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AddDirectoryResponse.ProtoReflect.Descriptor instead.
-func (*AddDirectoryResponse) Descriptor() ([]byte, []int) {
-	return file_daemon_proto_rawDescGZIP(), []int{6}
-}
-
-func (x *AddDirectoryResponse) GetStatus() ADD_DIRECTORY_STATUS {
-	if x != nil {
-		return x.Status
-	}
-	return ADD_DIRECTORY_STATUS_PARTIAL
+// Deprecated: Use Host.ProtoReflect.Descriptor instead.
+func (*Host) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{18}
 }
 
-func (x *AddDirectoryResponse) GetError() string {
+func (x *Host) GetName() string {
 	if x != nil {
-		return x.Error
+		return x.Name
 	}
 	return ""
 }
 
-func (x *AddDirectoryResponse) GetPath() string {
+func (x *Host) GetCurrent() bool {
 	if x != nil {
-		return x.Path
+		return x.Current
 	}
-	return ""
+	return false
 }
 
-type ResponseList struct {
+type HostList struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Values []*AddDirectoryResponse `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	Hosts []*Host `protobuf:"bytes,1,rep,name=hosts,proto3" json:"hosts,omitempty"`
 }
 
-func (x *ResponseList) Reset() {
-	*x = ResponseList{}
+func (x *HostList) Reset() {
+	*x = HostList{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_daemon_proto_msgTypes[7]
+		mi := &file_daemon_proto_msgTypes[19]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ResponseList) String() string {
+func (x *HostList) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ResponseList) ProtoMessage() {}
+func (*HostList) ProtoMessage() {}
 
-func (x *ResponseList) ProtoReflect() protoreflect.Message {
-	mi := &file_daemon_proto_msgTypes[7]
+func (x *HostList) ProtoReflect() protoreflect.Message {
+	mi := &file_daemon_proto_msgTypes[19]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -692,14 +2009,14 @@ func (x *ResponseList) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ResponseList.ProtoReflect.Descriptor instead.
-func (*ResponseList) Descriptor() ([]byte, []int) {
-	return file_daemon_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use HostList.ProtoReflect.Descriptor instead.
+func (*HostList) Descriptor() ([]byte, []int) {
+	return file_daemon_proto_rawDescGZIP(), []int{19}
 }
 
-func (x *ResponseList) GetValues() []*AddDirectoryResponse {
+func (x *HostList) GetHosts() []*Host {
 	if x != nil {
-		return x.Values
+		return x.Hosts
 	}
 	return nil
 }
@@ -713,7 +2030,7 @@ type Empty struct {
 func (x *Empty) Reset() {
 	*x = Empty{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_daemon_proto_msgTypes[8]
+		mi := &file_daemon_proto_msgTypes[20]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -726,7 +2043,7 @@ func (x *Empty) String() string {
 func (*Empty) ProtoMessage() {}
 
 func (x *Empty) ProtoReflect() protoreflect.Message {
-	mi := &file_daemon_proto_msgTypes[8]
+	mi := &file_daemon_proto_msgTypes[20]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -739,14 +2056,14 @@ func (x *Empty) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Empty.ProtoReflect.Descriptor instead.
 func (*Empty) Descriptor() ([]byte, []int) {
-	return file_daemon_proto_rawDescGZIP(), []int{8}
+	return file_daemon_proto_rawDescGZIP(), []int{20}
 }
 
 var File_daemon_proto protoreflect.FileDescriptor
 
 var file_daemon_proto_rawDesc = []byte{
 	0x0a, 0x0c, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x09,
-	0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x22, 0xf7, 0x01, 0x0a, 0x04, 0x4e, 0x6f,
+	0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x22, 0xb8, 0x04, 0x0a, 0x04, 0x4e, 0x6f,
 	0x64, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02,
 	0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
 	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x15, 0x0a, 0x06, 0x69, 0x73, 0x5f, 0x64, 0x69, 0x72,
@@ -762,84 +2079,280 @@ var file_daemon_proto_rawDesc = []byte{
 	0x70, 0x61, 0x74, 0x68, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x61, 0x62, 0x73, 0x6f,
 	0x6c, 0x75, 0x74, 0x65, 0x50, 0x61, 0x74, 0x68, 0x12, 0x19, 0x0a, 0x08, 0x64, 0x72, 0x69, 0x76,
 	0x65, 0x5f, 0x69, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x64, 0x72, 0x69, 0x76,
-	0x65, 0x49, 0x64, 0x22, 0x6f, 0x0a, 0x09, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x69, 0x73, 0x74,
-	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64,
-	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
-	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x61, 0x62, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x65,
-	0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x61, 0x62, 0x73,
-	0x6f, 0x6c, 0x75, 0x74, 0x65, 0x50, 0x61, 0x74, 0x68, 0x12, 0x19, 0x0a, 0x08, 0x64, 0x72, 0x69,
-	0x76, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x64, 0x72, 0x69,
-	0x76, 0x65, 0x49, 0x64, 0x22, 0x5b, 0x0a, 0x0b, 0x4f, 0x41, 0x75, 0x74, 0x68, 0x32, 0x54, 0x6f,
-	0x6b, 0x65, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52,
-	0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x04, 0x72, 0x6f, 0x6f, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x76,
-	0x61, 0x6c, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x22, 0x88, 0x01, 0x0a, 0x0b, 0x44, 0x72, 0x69, 0x76, 0x65, 0x52, 0x65, 0x63, 0x6f, 0x72,
-	0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69,
-	0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x5f, 0x70,
-	0x61, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6c, 0x6f, 0x63, 0x61, 0x6c,
+	0x65, 0x49, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x75, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x5f, 0x73, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x75, 0x70, 0x6c,
+	0x6f, 0x61, 0x64, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x23, 0x0a, 0x0d, 0x75, 0x70,
+	0x6c, 0x6f, 0x61, 0x64, 0x5f, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0c, 0x75, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12,
+	0x1f, 0x0a, 0x0b, 0x75, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x75, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x53, 0x69, 0x7a, 0x65,
+	0x12, 0x26, 0x0a, 0x0f, 0x75, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x5f, 0x6d, 0x6f, 0x64, 0x5f, 0x74,
+	0x69, 0x6d, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x75, 0x70, 0x6c, 0x6f, 0x61,
+	0x64, 0x4d, 0x6f, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x1d,
+	0x0a, 0x0a, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x6d, 0x64, 0x35, 0x18, 0x0d, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x4d, 0x64, 0x35, 0x12, 0x30, 0x0a,
+	0x14, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64,
+	0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x72, 0x65, 0x6d,
+	0x6f, 0x74, 0x65, 0x4d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x69, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05,
+	0x69, 0x6e, 0x6f, 0x64, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x10, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x6f, 0x64,
+	0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x11, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x6d, 0x6f, 0x64,
+	0x54, 0x69, 0x6d, 0x65, 0x22, 0xc4, 0x01, 0x0a, 0x09, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x69,
+	0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02,
+	0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x61, 0x62, 0x73, 0x6f, 0x6c, 0x75,
+	0x74, 0x65, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x61,
+	0x62, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x65, 0x50, 0x61, 0x74, 0x68, 0x12, 0x19, 0x0a, 0x08, 0x64,
+	0x72, 0x69, 0x76, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x64,
+	0x72, 0x69, 0x76, 0x65, 0x49, 0x64, 0x12, 0x3d, 0x0a, 0x0d, 0x68, 0x69, 0x64, 0x64, 0x65, 0x6e,
+	0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e,
+	0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x48, 0x49, 0x44, 0x44, 0x45, 0x4e,
+	0x5f, 0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59, 0x52, 0x0c, 0x68, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x50,
+	0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x69, 0x6e, 0x6f, 0x64, 0x65, 0x22, 0xe6, 0x01, 0x0a, 0x0b,
+	0x4f, 0x41, 0x75, 0x74, 0x68, 0x32, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x72,
+	0x6f, 0x6f, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x6f, 0x6f, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68,
+	0x6f, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x62, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x62, 0x61, 0x63, 0x6b,
+	0x65, 0x6e, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x5f, 0x74,
+	0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x73, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6c, 0x69, 0x65,
+	0x6e, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x73, 0x75, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x63,
+	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x63, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x22, 0x3b, 0x0a, 0x0d, 0x4c, 0x6f, 0x67, 0x6f, 0x75, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2a, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e,
+	0x4c, 0x4f, 0x47, 0x4f, 0x55, 0x54, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x52, 0x04, 0x6d, 0x6f, 0x64,
+	0x65, 0x22, 0x25, 0x0a, 0x0b, 0x4f, 0x41, 0x75, 0x74, 0x68, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x22, 0xe9, 0x02, 0x0a, 0x0a, 0x41, 0x75, 0x74,
+	0x68, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x6c, 0x6f, 0x67, 0x67, 0x65,
+	0x64, 0x5f, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x6c, 0x6f, 0x67, 0x67,
+	0x65, 0x64, 0x49, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74,
+	0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x12, 0x14, 0x0a, 0x05,
+	0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x6d, 0x61,
+	0x69, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x06, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x78,
+	0x70, 0x69, 0x72, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x65, 0x78, 0x70, 0x69,
+	0x72, 0x79, 0x12, 0x24, 0x0a, 0x0e, 0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x66, 0x6f, 0x6c, 0x64, 0x65,
+	0x72, 0x5f, 0x69, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x72, 0x6f, 0x6f, 0x74,
+	0x46, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x49, 0x64, 0x12, 0x24, 0x0a, 0x0e, 0x68, 0x6f, 0x73, 0x74,
+	0x5f, 0x66, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0c, 0x68, 0x6f, 0x73, 0x74, 0x46, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x49, 0x64, 0x12, 0x14,
+	0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x69,
+	0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49,
+	0x64, 0x12, 0x27, 0x0a, 0x0f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x61, 0x63, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x73, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x41, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75,
+	0x62, 0x6a, 0x65, 0x63, 0x74, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x73, 0x75, 0x62,
+	0x6a, 0x65, 0x63, 0x74, 0x22, 0x88, 0x01, 0x0a, 0x0b, 0x44, 0x72, 0x69, 0x76, 0x65, 0x52, 0x65,
+	0x63, 0x6f, 0x72, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x6f, 0x63, 0x61,
+	0x6c, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6c, 0x6f,
+	0x63, 0x61, 0x6c, 0x50, 0x61, 0x74, 0x68, 0x12, 0x19, 0x0a, 0x08, 0x64, 0x72, 0x69, 0x76, 0x65,
+	0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x64, 0x72, 0x69, 0x76, 0x65,
+	0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x22,
+	0xdf, 0x02, 0x0a, 0x03, 0x4a, 0x6f, 0x62, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x2d, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61,
+	0x74, 0x65, 0x64, 0x2e, 0x4a, 0x4f, 0x42, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x52, 0x06,
+	0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2d, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74,
+	0x65, 0x64, 0x2e, 0x4a, 0x4f, 0x42, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x52, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x19, 0x0a, 0x08, 0x6f, 0x6c, 0x64,
+	0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6f, 0x6c, 0x64,
 	0x50, 0x61, 0x74, 0x68, 0x12, 0x19, 0x0a, 0x08, 0x64, 0x72, 0x69, 0x76, 0x65, 0x5f, 0x69, 0x64,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x64, 0x72, 0x69, 0x76, 0x65, 0x49, 0x64, 0x12,
-	0x1b, 0x0a, 0x09, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x08, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x22, 0x22, 0x0a, 0x08,
-	0x50, 0x61, 0x74, 0x68, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73,
-	0x22, 0x73, 0x0a, 0x08, 0x46, 0x69, 0x6c, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x3a, 0x0a, 0x0d,
-	0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x4c, 0x69, 0x73, 0x74, 0x18, 0x01, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e,
-	0x57, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x0d, 0x64, 0x69, 0x72, 0x65, 0x63,
-	0x74, 0x6f, 0x72, 0x79, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x2b, 0x0a, 0x08, 0x66, 0x69, 0x6c, 0x65,
-	0x4c, 0x69, 0x73, 0x74, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x67, 0x65, 0x6e,
-	0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x08, 0x66, 0x69, 0x6c,
-	0x65, 0x4c, 0x69, 0x73, 0x74, 0x22, 0x79, 0x0a, 0x14, 0x41, 0x64, 0x64, 0x44, 0x69, 0x72, 0x65,
-	0x63, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a,
-	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e,
-	0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x41, 0x44, 0x44, 0x5f, 0x44, 0x49,
-	0x52, 0x45, 0x43, 0x54, 0x4f, 0x52, 0x59, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x52, 0x06,
-	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04,
-	0x70, 0x61, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68,
-	0x22, 0x47, 0x0a, 0x0c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4c, 0x69, 0x73, 0x74,
-	0x12, 0x37, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
-	0x32, 0x1f, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x41, 0x64, 0x64,
-	0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x22, 0x07, 0x0a, 0x05, 0x45, 0x6d, 0x70,
-	0x74, 0x79, 0x2a, 0x5a, 0x0a, 0x0b, 0x46, 0x49, 0x4c, 0x45, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55,
-	0x53, 0x12, 0x0e, 0x0a, 0x0a, 0x55, 0x4e, 0x4d, 0x4f, 0x44, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10,
-	0x00, 0x12, 0x0c, 0x0a, 0x08, 0x4d, 0x4f, 0x44, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x01, 0x12,
-	0x0c, 0x0a, 0x08, 0x55, 0x50, 0x4c, 0x4f, 0x41, 0x44, 0x45, 0x44, 0x10, 0x03, 0x12, 0x10, 0x0a,
-	0x0c, 0x4e, 0x4f, 0x54, 0x5f, 0x55, 0x50, 0x4c, 0x4f, 0x41, 0x44, 0x45, 0x44, 0x10, 0x04, 0x12,
-	0x0d, 0x0a, 0x09, 0x55, 0x4e, 0x54, 0x52, 0x41, 0x43, 0x4b, 0x45, 0x44, 0x10, 0x05, 0x2a, 0x58,
-	0x0a, 0x0c, 0x46, 0x49, 0x4c, 0x45, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x53, 0x12, 0x0d,
-	0x0a, 0x09, 0x41, 0x44, 0x44, 0x5f, 0x4e, 0x4f, 0x44, 0x45, 0x53, 0x10, 0x00, 0x12, 0x10, 0x0a,
-	0x0c, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x5f, 0x4e, 0x4f, 0x44, 0x45, 0x53, 0x10, 0x01, 0x12,
-	0x11, 0x0a, 0x0d, 0x41, 0x44, 0x44, 0x5f, 0x57, 0x41, 0x54, 0x43, 0x48, 0x4c, 0x49, 0x53, 0x54,
-	0x10, 0x02, 0x12, 0x14, 0x0a, 0x10, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x5f, 0x57, 0x41, 0x54,
-	0x43, 0x48, 0x4c, 0x49, 0x53, 0x54, 0x10, 0x03, 0x2a, 0x3d, 0x0a, 0x14, 0x41, 0x44, 0x44, 0x5f,
-	0x44, 0x49, 0x52, 0x45, 0x43, 0x54, 0x4f, 0x52, 0x59, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53,
-	0x12, 0x0b, 0x0a, 0x07, 0x50, 0x41, 0x52, 0x54, 0x49, 0x41, 0x4c, 0x10, 0x00, 0x12, 0x0c, 0x0a,
-	0x08, 0x43, 0x4f, 0x4d, 0x50, 0x4c, 0x45, 0x54, 0x45, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x46,
-	0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x02, 0x32, 0x94, 0x01, 0x0a, 0x10, 0x57, 0x61, 0x74, 0x63,
-	0x68, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x35, 0x0a, 0x0c,
-	0x47, 0x65, 0x74, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x10, 0x2e, 0x67,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x64, 0x72, 0x69, 0x76, 0x65, 0x49, 0x64, 0x12,
+	0x1b, 0x0a, 0x09, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08,
+	0x61, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08,
+	0x61, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x1d,
+	0x0a, 0x0a, 0x6e, 0x6f, 0x74, 0x5f, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x18, 0x0a, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x09, 0x6e, 0x6f, 0x74, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x12, 0x32, 0x0a,
+	0x06, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e,
+	0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x43, 0x4f, 0x4e, 0x46, 0x4c, 0x49,
+	0x43, 0x54, 0x5f, 0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59, 0x52, 0x06, 0x70, 0x6f, 0x6c, 0x69, 0x63,
+	0x79, 0x22, 0x78, 0x0a, 0x08, 0x50, 0x61, 0x74, 0x68, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x16, 0x0a,
+	0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x73, 0x12, 0x42, 0x0a, 0x0d, 0x68, 0x69, 0x64, 0x64, 0x65, 0x6e, 0x5f,
+	0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x67,
+	0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x48, 0x49, 0x44, 0x44, 0x45, 0x4e, 0x5f,
+	0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59, 0x48, 0x00, 0x52, 0x0c, 0x68, 0x69, 0x64, 0x64, 0x65, 0x6e,
+	0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x88, 0x01, 0x01, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x68, 0x69,
+	0x64, 0x64, 0x65, 0x6e, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x22, 0x73, 0x0a, 0x08, 0x46,
+	0x69, 0x6c, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x3a, 0x0a, 0x0d, 0x64, 0x69, 0x72, 0x65, 0x63,
+	0x74, 0x6f, 0x72, 0x79, 0x4c, 0x69, 0x73, 0x74, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14,
+	0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68,
+	0x4c, 0x69, 0x73, 0x74, 0x52, 0x0d, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x4c,
+	0x69, 0x73, 0x74, 0x12, 0x2b, 0x0a, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65,
+	0x64, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x4c, 0x69, 0x73, 0x74,
+	0x22, 0x79, 0x0a, 0x14, 0x41, 0x64, 0x64, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72,
+	0x61, 0x74, 0x65, 0x64, 0x2e, 0x41, 0x44, 0x44, 0x5f, 0x44, 0x49, 0x52, 0x45, 0x43, 0x54, 0x4f,
+	0x52, 0x59, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x22, 0x47, 0x0a, 0x0c, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x37, 0x0a, 0x06, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x67, 0x65,
+	0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x41, 0x64, 0x64, 0x44, 0x69, 0x72, 0x65, 0x63,
+	0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x06, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x73, 0x22, 0x5c, 0x0a, 0x12, 0x43, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74,
+	0x52, 0x65, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61,
+	0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x32,
+	0x0a, 0x06, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1a,
+	0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x43, 0x4f, 0x4e, 0x46, 0x4c,
+	0x49, 0x43, 0x54, 0x5f, 0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59, 0x52, 0x06, 0x70, 0x6f, 0x6c, 0x69,
+	0x63, 0x79, 0x22, 0x6e, 0x0a, 0x0b, 0x50, 0x75, 0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x02, 0x74, 0x6f, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x72, 0x79, 0x5f, 0x72, 0x75, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x12, 0x12,
+	0x0a, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x6f,
+	0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x61, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02,
+	0x61, 0x74, 0x22, 0x94, 0x01, 0x0a, 0x0c, 0x50, 0x75, 0x6c, 0x6c, 0x50, 0x72, 0x6f, 0x67, 0x72,
+	0x65, 0x73, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x2e, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61,
+	0x74, 0x65, 0x64, 0x2e, 0x50, 0x55, 0x4c, 0x4c, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x52,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6f,
+	0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6f, 0x66, 0x66,
+	0x73, 0x65, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x25, 0x0a, 0x0f, 0x52, 0x65, 0x76,
+	0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04,
+	0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68,
+	0x22, 0x88, 0x01, 0x0a, 0x08, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x23, 0x0a,
+	0x0d, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x54, 0x69,
+	0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x64, 0x35, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6d, 0x64, 0x35, 0x12, 0x21, 0x0a, 0x0c, 0x6b, 0x65, 0x65, 0x70,
+	0x5f, 0x66, 0x6f, 0x72, 0x65, 0x76, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b,
+	0x6b, 0x65, 0x65, 0x70, 0x46, 0x6f, 0x72, 0x65, 0x76, 0x65, 0x72, 0x22, 0x55, 0x0a, 0x0c, 0x52,
+	0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70,
+	0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12,
+	0x31, 0x0a, 0x09, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x13, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x52,
+	0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x73, 0x22, 0x34, 0x0a, 0x04, 0x48, 0x6f, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x07, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x22, 0x31, 0x0a, 0x08, 0x48, 0x6f, 0x73, 0x74,
+	0x4c, 0x69, 0x73, 0x74, 0x12, 0x25, 0x0a, 0x05, 0x68, 0x6f, 0x73, 0x74, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e,
+	0x48, 0x6f, 0x73, 0x74, 0x52, 0x05, 0x68, 0x6f, 0x73, 0x74, 0x73, 0x22, 0x07, 0x0a, 0x05, 0x45,
+	0x6d, 0x70, 0x74, 0x79, 0x2a, 0x7b, 0x0a, 0x0b, 0x46, 0x49, 0x4c, 0x45, 0x5f, 0x53, 0x54, 0x41,
+	0x54, 0x55, 0x53, 0x12, 0x0e, 0x0a, 0x0a, 0x55, 0x4e, 0x4d, 0x4f, 0x44, 0x49, 0x46, 0x49, 0x45,
+	0x44, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x4d, 0x4f, 0x44, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10,
+	0x01, 0x12, 0x0c, 0x0a, 0x08, 0x55, 0x50, 0x4c, 0x4f, 0x41, 0x44, 0x45, 0x44, 0x10, 0x03, 0x12,
+	0x10, 0x0a, 0x0c, 0x4e, 0x4f, 0x54, 0x5f, 0x55, 0x50, 0x4c, 0x4f, 0x41, 0x44, 0x45, 0x44, 0x10,
+	0x04, 0x12, 0x0d, 0x0a, 0x09, 0x55, 0x4e, 0x54, 0x52, 0x41, 0x43, 0x4b, 0x45, 0x44, 0x10, 0x05,
+	0x12, 0x11, 0x0a, 0x0d, 0x55, 0x50, 0x4c, 0x4f, 0x41, 0x44, 0x5f, 0x46, 0x41, 0x49, 0x4c, 0x45,
+	0x44, 0x10, 0x06, 0x12, 0x0c, 0x0a, 0x08, 0x43, 0x4f, 0x4e, 0x46, 0x4c, 0x49, 0x43, 0x54, 0x10,
+	0x07, 0x2a, 0x58, 0x0a, 0x0c, 0x46, 0x49, 0x4c, 0x45, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e,
+	0x53, 0x12, 0x0d, 0x0a, 0x09, 0x41, 0x44, 0x44, 0x5f, 0x4e, 0x4f, 0x44, 0x45, 0x53, 0x10, 0x00,
+	0x12, 0x10, 0x0a, 0x0c, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x5f, 0x4e, 0x4f, 0x44, 0x45, 0x53,
+	0x10, 0x01, 0x12, 0x11, 0x0a, 0x0d, 0x41, 0x44, 0x44, 0x5f, 0x57, 0x41, 0x54, 0x43, 0x48, 0x4c,
+	0x49, 0x53, 0x54, 0x10, 0x02, 0x12, 0x14, 0x0a, 0x10, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x5f,
+	0x57, 0x41, 0x54, 0x43, 0x48, 0x4c, 0x49, 0x53, 0x54, 0x10, 0x03, 0x2a, 0x3d, 0x0a, 0x14, 0x41,
+	0x44, 0x44, 0x5f, 0x44, 0x49, 0x52, 0x45, 0x43, 0x54, 0x4f, 0x52, 0x59, 0x5f, 0x53, 0x54, 0x41,
+	0x54, 0x55, 0x53, 0x12, 0x0b, 0x0a, 0x07, 0x50, 0x41, 0x52, 0x54, 0x49, 0x41, 0x4c, 0x10, 0x00,
+	0x12, 0x0c, 0x0a, 0x08, 0x43, 0x4f, 0x4d, 0x50, 0x4c, 0x45, 0x54, 0x45, 0x10, 0x01, 0x12, 0x0a,
+	0x0a, 0x06, 0x46, 0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x02, 0x2a, 0x50, 0x0a, 0x0d, 0x48, 0x49,
+	0x44, 0x44, 0x45, 0x4e, 0x5f, 0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59, 0x12, 0x17, 0x0a, 0x13, 0x45,
+	0x58, 0x43, 0x4c, 0x55, 0x44, 0x45, 0x5f, 0x48, 0x49, 0x44, 0x44, 0x45, 0x4e, 0x5f, 0x44, 0x49,
+	0x52, 0x53, 0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e, 0x49, 0x4e, 0x43, 0x4c, 0x55, 0x44, 0x45, 0x5f,
+	0x48, 0x49, 0x44, 0x44, 0x45, 0x4e, 0x10, 0x01, 0x12, 0x12, 0x0a, 0x0e, 0x45, 0x58, 0x43, 0x4c,
+	0x55, 0x44, 0x45, 0x5f, 0x48, 0x49, 0x44, 0x44, 0x45, 0x4e, 0x10, 0x02, 0x2a, 0x46, 0x0a, 0x0b,
+	0x4c, 0x4f, 0x47, 0x4f, 0x55, 0x54, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x12, 0x0e, 0x0a, 0x0a, 0x4b,
+	0x45, 0x45, 0x50, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x10, 0x00, 0x12, 0x15, 0x0a, 0x11, 0x46,
+	0x4f, 0x52, 0x47, 0x45, 0x54, 0x5f, 0x52, 0x45, 0x4d, 0x4f, 0x54, 0x45, 0x5f, 0x49, 0x44, 0x53,
+	0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c, 0x50, 0x55, 0x52, 0x47, 0x45, 0x5f, 0x52, 0x45, 0x4d, 0x4f,
+	0x54, 0x45, 0x10, 0x02, 0x2a, 0x66, 0x0a, 0x0a, 0x4a, 0x4f, 0x42, 0x5f, 0x41, 0x43, 0x54, 0x49,
+	0x4f, 0x4e, 0x12, 0x0d, 0x0a, 0x09, 0x53, 0x59, 0x4e, 0x43, 0x5f, 0x46, 0x49, 0x4c, 0x45, 0x10,
+	0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x53, 0x59, 0x4e, 0x43, 0x5f, 0x46, 0x4f, 0x4c, 0x44, 0x45, 0x52,
+	0x10, 0x01, 0x12, 0x11, 0x0a, 0x0d, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x5f, 0x52, 0x45, 0x4d,
+	0x4f, 0x54, 0x45, 0x10, 0x02, 0x12, 0x0f, 0x0a, 0x0b, 0x4d, 0x4f, 0x56, 0x45, 0x5f, 0x52, 0x45,
+	0x4d, 0x4f, 0x54, 0x45, 0x10, 0x03, 0x12, 0x14, 0x0a, 0x10, 0x52, 0x45, 0x53, 0x4f, 0x4c, 0x56,
+	0x45, 0x5f, 0x43, 0x4f, 0x4e, 0x46, 0x4c, 0x49, 0x43, 0x54, 0x10, 0x04, 0x2a, 0x4d, 0x0a, 0x0f,
+	0x43, 0x4f, 0x4e, 0x46, 0x4c, 0x49, 0x43, 0x54, 0x5f, 0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59, 0x12,
+	0x0d, 0x0a, 0x09, 0x4b, 0x45, 0x45, 0x50, 0x5f, 0x42, 0x4f, 0x54, 0x48, 0x10, 0x00, 0x12, 0x0e,
+	0x0a, 0x0a, 0x4c, 0x4f, 0x43, 0x41, 0x4c, 0x5f, 0x57, 0x49, 0x4e, 0x53, 0x10, 0x01, 0x12, 0x0f,
+	0x0a, 0x0b, 0x52, 0x45, 0x4d, 0x4f, 0x54, 0x45, 0x5f, 0x57, 0x49, 0x4e, 0x53, 0x10, 0x02, 0x12,
+	0x0a, 0x0a, 0x06, 0x4d, 0x41, 0x4e, 0x55, 0x41, 0x4c, 0x10, 0x03, 0x2a, 0x26, 0x0a, 0x0a, 0x4a,
+	0x4f, 0x42, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x12, 0x0b, 0x0a, 0x07, 0x50, 0x45, 0x4e,
+	0x44, 0x49, 0x4e, 0x47, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x52, 0x55, 0x4e, 0x4e, 0x49, 0x4e,
+	0x47, 0x10, 0x01, 0x2a, 0x7d, 0x0a, 0x0b, 0x50, 0x55, 0x4c, 0x4c, 0x5f, 0x53, 0x54, 0x41, 0x54,
+	0x55, 0x53, 0x12, 0x0f, 0x0a, 0x0b, 0x44, 0x4f, 0x57, 0x4e, 0x4c, 0x4f, 0x41, 0x44, 0x49, 0x4e,
+	0x47, 0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a, 0x44, 0x4f, 0x57, 0x4e, 0x4c, 0x4f, 0x41, 0x44, 0x45,
+	0x44, 0x10, 0x01, 0x12, 0x0e, 0x0a, 0x0a, 0x55, 0x50, 0x5f, 0x54, 0x4f, 0x5f, 0x44, 0x41, 0x54,
+	0x45, 0x10, 0x02, 0x12, 0x12, 0x0a, 0x0e, 0x57, 0x4f, 0x55, 0x4c, 0x44, 0x5f, 0x44, 0x4f, 0x57,
+	0x4e, 0x4c, 0x4f, 0x41, 0x44, 0x10, 0x03, 0x12, 0x14, 0x0a, 0x10, 0x44, 0x4f, 0x57, 0x4e, 0x4c,
+	0x4f, 0x41, 0x44, 0x5f, 0x53, 0x4b, 0x49, 0x50, 0x50, 0x45, 0x44, 0x10, 0x04, 0x12, 0x13, 0x0a,
+	0x0f, 0x44, 0x4f, 0x57, 0x4e, 0x4c, 0x4f, 0x41, 0x44, 0x5f, 0x46, 0x41, 0x49, 0x4c, 0x45, 0x44,
+	0x10, 0x05, 0x32, 0xc3, 0x03, 0x0a, 0x10, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x69, 0x73, 0x74,
+	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x35, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x57, 0x61,
+	0x74, 0x63, 0x68, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x10, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61,
+	0x74, 0x65, 0x64, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x13, 0x2e, 0x67, 0x65, 0x6e, 0x65,
+	0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x49,
+	0x0a, 0x19, 0x41, 0x64, 0x64, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x69, 0x65, 0x73,
+	0x54, 0x6f, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x13, 0x2e, 0x67, 0x65,
+	0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x50, 0x61, 0x74, 0x68, 0x4c, 0x69, 0x73, 0x74,
+	0x1a, 0x17, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x35, 0x0a, 0x0c, 0x47, 0x65, 0x74,
+	0x43, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x73, 0x12, 0x10, 0x2e, 0x67, 0x65, 0x6e, 0x65,
+	0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x13, 0x2e, 0x67, 0x65,
+	0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x4c, 0x69, 0x73, 0x74,
+	0x12, 0x42, 0x0a, 0x0f, 0x52, 0x65, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x6c,
+	0x69, 0x63, 0x74, 0x12, 0x1d, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e,
+	0x43, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x52, 0x65, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x69,
+	0x6f, 0x6e, 0x1a, 0x10, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x45,
+	0x6d, 0x70, 0x74, 0x79, 0x12, 0x39, 0x0a, 0x04, 0x50, 0x75, 0x6c, 0x6c, 0x12, 0x16, 0x2e, 0x67,
+	0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x50, 0x75, 0x6c, 0x6c, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64,
+	0x2e, 0x50, 0x75, 0x6c, 0x6c, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x30, 0x01, 0x12,
+	0x32, 0x0a, 0x09, 0x4c, 0x69, 0x73, 0x74, 0x48, 0x6f, 0x73, 0x74, 0x73, 0x12, 0x10, 0x2e, 0x67,
 	0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x13,
-	0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x4c,
-	0x69, 0x73, 0x74, 0x12, 0x49, 0x0a, 0x19, 0x41, 0x64, 0x64, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74,
-	0x6f, 0x72, 0x69, 0x65, 0x73, 0x54, 0x6f, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x69, 0x73, 0x74,
-	0x12, 0x13, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x50, 0x61, 0x74,
-	0x68, 0x4c, 0x69, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65,
-	0x64, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x32, 0x84,
-	0x01, 0x0a, 0x15, 0x41, 0x75, 0x74, 0x68, 0x65, 0x6e, 0x74, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x35, 0x0a, 0x09, 0x53, 0x61, 0x76, 0x65,
-	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x16, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65,
-	0x64, 0x2e, 0x4f, 0x41, 0x75, 0x74, 0x68, 0x32, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x1a, 0x10, 0x2e,
-	0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12,
-	0x34, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x10, 0x2e, 0x67, 0x65,
-	0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x16, 0x2e,
-	0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x4f, 0x41, 0x75, 0x74, 0x68, 0x32,
-	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x42, 0x0c, 0x5a, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74,
+	0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x4c,
+	0x69, 0x73, 0x74, 0x12, 0x43, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x1a, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e,
+	0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x17, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x52, 0x65, 0x76, 0x69,
+	0x73, 0x69, 0x6f, 0x6e, 0x4c, 0x69, 0x73, 0x74, 0x32, 0xfa, 0x01, 0x0a, 0x15, 0x41, 0x75, 0x74,
+	0x68, 0x65, 0x6e, 0x74, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x12, 0x35, 0x0a, 0x09, 0x53, 0x61, 0x76, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12,
+	0x16, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x4f, 0x41, 0x75, 0x74,
+	0x68, 0x32, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x1a, 0x10, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61,
+	0x74, 0x65, 0x64, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x38, 0x0a, 0x0d, 0x47, 0x65, 0x74,
+	0x41, 0x75, 0x74, 0x68, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x10, 0x2e, 0x67, 0x65, 0x6e,
+	0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x15, 0x2e, 0x67,
+	0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x12, 0x3a, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x4f, 0x41, 0x75, 0x74, 0x68, 0x43,
+	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x12, 0x10, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65,
+	0x64, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x16, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61,
+	0x74, 0x65, 0x64, 0x2e, 0x4f, 0x41, 0x75, 0x74, 0x68, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x12,
+	0x34, 0x0a, 0x06, 0x4c, 0x6f, 0x67, 0x6f, 0x75, 0x74, 0x12, 0x18, 0x2e, 0x67, 0x65, 0x6e, 0x65,
+	0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x4c, 0x6f, 0x67, 0x6f, 0x75, 0x74, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e,
+	0x45, 0x6d, 0x70, 0x74, 0x79, 0x42, 0x0c, 0x5a, 0x0a, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74,
 	0x65, 0x64, 0x2f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
@@ -855,42 +2368,84 @@ func file_daemon_proto_rawDescGZIP() []byte {
 	return file_daemon_proto_rawDescData
 }
 
-var file_daemon_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_daemon_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_daemon_proto_enumTypes = make([]protoimpl.EnumInfo, 9)
+var file_daemon_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
 var file_daemon_proto_goTypes = []any{
 	(FILE_STATUS)(0),             // 0: generated.FILE_STATUS
 	(FILE_ACTIONS)(0),            // 1: generated.FILE_ACTIONS
 	(ADD_DIRECTORY_STATUS)(0),    // 2: generated.ADD_DIRECTORY_STATUS
-	(*Node)(nil),                 // 3: generated.Node
-	(*WatchList)(nil),            // 4: generated.WatchList
-	(*OAuth2Token)(nil),          // 5: generated.OAuth2Token
-	(*DriveRecord)(nil),          // 6: generated.DriveRecord
-	(*PathList)(nil),             // 7: generated.PathList
-	(*FileList)(nil),             // 8: generated.FileList
-	(*AddDirectoryResponse)(nil), // 9: generated.AddDirectoryResponse
-	(*ResponseList)(nil),         // 10: generated.ResponseList
-	(*Empty)(nil),                // 11: generated.Empty
+	(HIDDEN_POLICY)(0),           // 3: generated.HIDDEN_POLICY
+	(LOGOUT_MODE)(0),             // 4: generated.LOGOUT_MODE
+	(JOB_ACTION)(0),              // 5: generated.JOB_ACTION
+	(CONFLICT_POLICY)(0),         // 6: generated.CONFLICT_POLICY
+	(JOB_STATUS)(0),              // 7: generated.JOB_STATUS
+	(PULL_STATUS)(0),             // 8: generated.PULL_STATUS
+	(*Node)(nil),                 // 9: generated.Node
+	(*WatchList)(nil),            // 10: generated.WatchList
+	(*OAuth2Token)(nil),          // 11: generated.OAuth2Token
+	(*LogoutRequest)(nil),        // 12: generated.LogoutRequest
+	(*OAuthClient)(nil),          // 13: generated.OAuthClient
+	(*AuthStatus)(nil),           // 14: generated.AuthStatus
+	(*DriveRecord)(nil),          // 15: generated.DriveRecord
+	(*Job)(nil),                  // 16: generated.Job
+	(*PathList)(nil),             // 17: generated.PathList
+	(*FileList)(nil),             // 18: generated.FileList
+	(*AddDirectoryResponse)(nil), // 19: generated.AddDirectoryResponse
+	(*ResponseList)(nil),         // 20: generated.ResponseList
+	(*ConflictResolution)(nil),   // 21: generated.ConflictResolution
+	(*PullRequest)(nil),          // 22: generated.PullRequest
+	(*PullProgress)(nil),         // 23: generated.PullProgress
+	(*RevisionRequest)(nil),      // 24: generated.RevisionRequest
+	(*Revision)(nil),             // 25: generated.Revision
+	(*RevisionList)(nil),         // 26: generated.RevisionList
+	(*Host)(nil),                 // 27: generated.Host
+	(*HostList)(nil),             // 28: generated.HostList
+	(*Empty)(nil),                // 29: generated.Empty
 }
 var file_daemon_proto_depIdxs = []int32{
 	0,  // 0: generated.Node.file_status:type_name -> generated.FILE_STATUS
 	0,  // 1: generated.Node.upload_status:type_name -> generated.FILE_STATUS
-	4,  // 2: generated.FileList.directoryList:type_name -> generated.WatchList
-	3,  // 3: generated.FileList.fileList:type_name -> generated.Node
-	2,  // 4: generated.AddDirectoryResponse.status:type_name -> generated.ADD_DIRECTORY_STATUS
-	9,  // 5: generated.ResponseList.values:type_name -> generated.AddDirectoryResponse
-	11, // 6: generated.WatchListService.GetWatchList:input_type -> generated.Empty
-	7,  // 7: generated.WatchListService.AddDirectoriesToWatchList:input_type -> generated.PathList
-	5,  // 8: generated.AuthenticationService.SaveToken:input_type -> generated.OAuth2Token
-	11, // 9: generated.AuthenticationService.GetToken:input_type -> generated.Empty
-	8,  // 10: generated.WatchListService.GetWatchList:output_type -> generated.FileList
-	10, // 11: generated.WatchListService.AddDirectoriesToWatchList:output_type -> generated.ResponseList
-	11, // 12: generated.AuthenticationService.SaveToken:output_type -> generated.Empty
-	5,  // 13: generated.AuthenticationService.GetToken:output_type -> generated.OAuth2Token
-	10, // [10:14] is the sub-list for method output_type
-	6,  // [6:10] is the sub-list for method input_type
-	6,  // [6:6] is the sub-list for extension type_name
-	6,  // [6:6] is the sub-list for extension extendee
-	0,  // [0:6] is the sub-list for field type_name
+	3,  // 2: generated.WatchList.hidden_policy:type_name -> generated.HIDDEN_POLICY
+	4,  // 3: generated.LogoutRequest.mode:type_name -> generated.LOGOUT_MODE
+	5,  // 4: generated.Job.action:type_name -> generated.JOB_ACTION
+	7,  // 5: generated.Job.status:type_name -> generated.JOB_STATUS
+	6,  // 6: generated.Job.policy:type_name -> generated.CONFLICT_POLICY
+	3,  // 7: generated.PathList.hidden_policy:type_name -> generated.HIDDEN_POLICY
+	10, // 8: generated.FileList.directoryList:type_name -> generated.WatchList
+	9,  // 9: generated.FileList.fileList:type_name -> generated.Node
+	2,  // 10: generated.AddDirectoryResponse.status:type_name -> generated.ADD_DIRECTORY_STATUS
+	19, // 11: generated.ResponseList.values:type_name -> generated.AddDirectoryResponse
+	6,  // 12: generated.ConflictResolution.policy:type_name -> generated.CONFLICT_POLICY
+	8,  // 13: generated.PullProgress.status:type_name -> generated.PULL_STATUS
+	25, // 14: generated.RevisionList.revisions:type_name -> generated.Revision
+	27, // 15: generated.HostList.hosts:type_name -> generated.Host
+	29, // 16: generated.WatchListService.GetWatchList:input_type -> generated.Empty
+	17, // 17: generated.WatchListService.AddDirectoriesToWatchList:input_type -> generated.PathList
+	29, // 18: generated.WatchListService.GetConflicts:input_type -> generated.Empty
+	21, // 19: generated.WatchListService.ResolveConflict:input_type -> generated.ConflictResolution
+	22, // 20: generated.WatchListService.Pull:input_type -> generated.PullRequest
+	29, // 21: generated.WatchListService.ListHosts:input_type -> generated.Empty
+	24, // 22: generated.WatchListService.GetRevisions:input_type -> generated.RevisionRequest
+	11, // 23: generated.AuthenticationService.SaveToken:input_type -> generated.OAuth2Token
+	29, // 24: generated.AuthenticationService.GetAuthStatus:input_type -> generated.Empty
+	29, // 25: generated.AuthenticationService.GetOAuthClient:input_type -> generated.Empty
+	12, // 26: generated.AuthenticationService.Logout:input_type -> generated.LogoutRequest
+	18, // 27: generated.WatchListService.GetWatchList:output_type -> generated.FileList
+	20, // 28: generated.WatchListService.AddDirectoriesToWatchList:output_type -> generated.ResponseList
+	18, // 29: generated.WatchListService.GetConflicts:output_type -> generated.FileList
+	29, // 30: generated.WatchListService.ResolveConflict:output_type -> generated.Empty
+	23, // 31: generated.WatchListService.Pull:output_type -> generated.PullProgress
+	28, // 32: generated.WatchListService.ListHosts:output_type -> generated.HostList
+	26, // 33: generated.WatchListService.GetRevisions:output_type -> generated.RevisionList
+	29, // 34: generated.AuthenticationService.SaveToken:output_type -> generated.Empty
+	14, // 35: generated.AuthenticationService.GetAuthStatus:output_type -> generated.AuthStatus
+	13, // 36: generated.AuthenticationService.GetOAuthClient:output_type -> generated.OAuthClient
+	29, // 37: generated.AuthenticationService.Logout:output_type -> generated.Empty
+	27, // [27:38] is the sub-list for method output_type
+	16, // [16:27] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
 }
 
 func init() { file_daemon_proto_init() }
@@ -936,7 +2491,7 @@ func file_daemon_proto_init() {
 			}
 		}
 		file_daemon_proto_msgTypes[3].Exporter = func(v any, i int) any {
-			switch v := v.(*DriveRecord); i {
+			switch v := v.(*LogoutRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -948,7 +2503,7 @@ func file_daemon_proto_init() {
 			}
 		}
 		file_daemon_proto_msgTypes[4].Exporter = func(v any, i int) any {
-			switch v := v.(*PathList); i {
+			switch v := v.(*OAuthClient); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -960,7 +2515,7 @@ func file_daemon_proto_init() {
 			}
 		}
 		file_daemon_proto_msgTypes[5].Exporter = func(v any, i int) any {
-			switch v := v.(*FileList); i {
+			switch v := v.(*AuthStatus); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -972,7 +2527,7 @@ func file_daemon_proto_init() {
 			}
 		}
 		file_daemon_proto_msgTypes[6].Exporter = func(v any, i int) any {
-			switch v := v.(*AddDirectoryResponse); i {
+			switch v := v.(*DriveRecord); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -984,7 +2539,7 @@ func file_daemon_proto_init() {
 			}
 		}
 		file_daemon_proto_msgTypes[7].Exporter = func(v any, i int) any {
-			switch v := v.(*ResponseList); i {
+			switch v := v.(*Job); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -996,6 +2551,150 @@ func file_daemon_proto_init() {
 			}
 		}
 		file_daemon_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*PathList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*FileList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*AddDirectoryResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*ResponseList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[12].Exporter = func(v any, i int) any {
+			switch v := v.(*ConflictResolution); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[13].Exporter = func(v any, i int) any {
+			switch v := v.(*PullRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[14].Exporter = func(v any, i int) any {
+			switch v := v.(*PullProgress); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[15].Exporter = func(v any, i int) any {
+			switch v := v.(*RevisionRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[16].Exporter = func(v any, i int) any {
+			switch v := v.(*Revision); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[17].Exporter = func(v any, i int) any {
+			switch v := v.(*RevisionList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[18].Exporter = func(v any, i int) any {
+			switch v := v.(*Host); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[19].Exporter = func(v any, i int) any {
+			switch v := v.(*HostList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_daemon_proto_msgTypes[20].Exporter = func(v any, i int) any {
 			switch v := v.(*Empty); i {
 			case 0:
 				return &v.state
@@ -1008,13 +2707,14 @@ func file_daemon_proto_init() {
 			}
 		}
 	}
+	file_daemon_proto_msgTypes[8].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_daemon_proto_rawDesc,
-			NumEnums:      3,
-			NumMessages:   9,
+			NumEnums:      9,
+			NumMessages:   21,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
@@ -21,6 +21,11 @@ const _ = grpc.SupportPackageIsVersion9
 const (
 	WatchListService_GetWatchList_FullMethodName              = "/generated.WatchListService/GetWatchList"
 	WatchListService_AddDirectoriesToWatchList_FullMethodName = "/generated.WatchListService/AddDirectoriesToWatchList"
+	WatchListService_GetConflicts_FullMethodName              = "/generated.WatchListService/GetConflicts"
+	WatchListService_ResolveConflict_FullMethodName           = "/generated.WatchListService/ResolveConflict"
+	WatchListService_Pull_FullMethodName                      = "/generated.WatchListService/Pull"
+	WatchListService_ListHosts_FullMethodName                 = "/generated.WatchListService/ListHosts"
+	WatchListService_GetRevisions_FullMethodName              = "/generated.WatchListService/GetRevisions"
 )
 
 // WatchListServiceClient is the client API for WatchListService service.
@@ -29,6 +34,11 @@ const (
 type WatchListServiceClient interface {
 	GetWatchList(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*FileList, error)
 	AddDirectoriesToWatchList(ctx context.Context, in *PathList, opts ...grpc.CallOption) (*ResponseList, error)
+	GetConflicts(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*FileList, error)
+	ResolveConflict(ctx context.Context, in *ConflictResolution, opts ...grpc.CallOption) (*Empty, error)
+	Pull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PullProgress], error)
+	ListHosts(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*HostList, error)
+	GetRevisions(ctx context.Context, in *RevisionRequest, opts ...grpc.CallOption) (*RevisionList, error)
 }
 
 type watchListServiceClient struct {
@@ -59,12 +69,76 @@ func (c *watchListServiceClient) AddDirectoriesToWatchList(ctx context.Context,
 	return out, nil
 }
 
+func (c *watchListServiceClient) GetConflicts(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*FileList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FileList)
+	err := c.cc.Invoke(ctx, WatchListService_GetConflicts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *watchListServiceClient) ResolveConflict(ctx context.Context, in *ConflictResolution, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, WatchListService_ResolveConflict_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *watchListServiceClient) Pull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PullProgress], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &WatchListService_ServiceDesc.Streams[0], WatchListService_Pull_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PullRequest, PullProgress]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WatchListService_PullClient = grpc.ServerStreamingClient[PullProgress]
+
+func (c *watchListServiceClient) ListHosts(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*HostList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HostList)
+	err := c.cc.Invoke(ctx, WatchListService_ListHosts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *watchListServiceClient) GetRevisions(ctx context.Context, in *RevisionRequest, opts ...grpc.CallOption) (*RevisionList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevisionList)
+	err := c.cc.Invoke(ctx, WatchListService_GetRevisions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // WatchListServiceServer is the server API for WatchListService service.
 // All implementations must embed UnimplementedWatchListServiceServer
 // for forward compatibility.
 type WatchListServiceServer interface {
 	GetWatchList(context.Context, *Empty) (*FileList, error)
 	AddDirectoriesToWatchList(context.Context, *PathList) (*ResponseList, error)
+	GetConflicts(context.Context, *Empty) (*FileList, error)
+	ResolveConflict(context.Context, *ConflictResolution) (*Empty, error)
+	Pull(*PullRequest, grpc.ServerStreamingServer[PullProgress]) error
+	ListHosts(context.Context, *Empty) (*HostList, error)
+	GetRevisions(context.Context, *RevisionRequest) (*RevisionList, error)
 	mustEmbedUnimplementedWatchListServiceServer()
 }
 
@@ -81,6 +155,21 @@ func (UnimplementedWatchListServiceServer) GetWatchList(context.Context, *Empty)
 func (UnimplementedWatchListServiceServer) AddDirectoriesToWatchList(context.Context, *PathList) (*ResponseList, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddDirectoriesToWatchList not implemented")
 }
+func (UnimplementedWatchListServiceServer) GetConflicts(context.Context, *Empty) (*FileList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConflicts not implemented")
+}
+func (UnimplementedWatchListServiceServer) ResolveConflict(context.Context, *ConflictResolution) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResolveConflict not implemented")
+}
+func (UnimplementedWatchListServiceServer) Pull(*PullRequest, grpc.ServerStreamingServer[PullProgress]) error {
+	return status.Errorf(codes.Unimplemented, "method Pull not implemented")
+}
+func (UnimplementedWatchListServiceServer) ListHosts(context.Context, *Empty) (*HostList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListHosts not implemented")
+}
+func (UnimplementedWatchListServiceServer) GetRevisions(context.Context, *RevisionRequest) (*RevisionList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRevisions not implemented")
+}
 func (UnimplementedWatchListServiceServer) mustEmbedUnimplementedWatchListServiceServer() {}
 func (UnimplementedWatchListServiceServer) testEmbeddedByValue()                          {}
 
@@ -138,6 +227,89 @@ func _WatchListService_AddDirectoriesToWatchList_Handler(srv interface{}, ctx co
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WatchListService_GetConflicts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WatchListServiceServer).GetConflicts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WatchListService_GetConflicts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WatchListServiceServer).GetConflicts(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WatchListService_ResolveConflict_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConflictResolution)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WatchListServiceServer).ResolveConflict(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WatchListService_ResolveConflict_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WatchListServiceServer).ResolveConflict(ctx, req.(*ConflictResolution))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WatchListService_Pull_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PullRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WatchListServiceServer).Pull(m, &grpc.GenericServerStream[PullRequest, PullProgress]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WatchListService_PullServer = grpc.ServerStreamingServer[PullProgress]
+
+func _WatchListService_ListHosts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WatchListServiceServer).ListHosts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WatchListService_ListHosts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WatchListServiceServer).ListHosts(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WatchListService_GetRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WatchListServiceServer).GetRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WatchListService_GetRevisions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WatchListServiceServer).GetRevisions(ctx, req.(*RevisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // WatchListService_ServiceDesc is the grpc.ServiceDesc for WatchListService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -153,14 +325,38 @@ var WatchListService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "AddDirectoriesToWatchList",
 			Handler:    _WatchListService_AddDirectoriesToWatchList_Handler,
 		},
+		{
+			MethodName: "GetConflicts",
+			Handler:    _WatchListService_GetConflicts_Handler,
+		},
+		{
+			MethodName: "ResolveConflict",
+			Handler:    _WatchListService_ResolveConflict_Handler,
+		},
+		{
+			MethodName: "ListHosts",
+			Handler:    _WatchListService_ListHosts_Handler,
+		},
+		{
+			MethodName: "GetRevisions",
+			Handler:    _WatchListService_GetRevisions_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Pull",
+			Handler:       _WatchListService_Pull_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "daemon.proto",
 }
 
 const (
-	AuthenticationService_SaveToken_FullMethodName = "/generated.AuthenticationService/SaveToken"
-	AuthenticationService_GetToken_FullMethodName  = "/generated.AuthenticationService/GetToken"
+	AuthenticationService_SaveToken_FullMethodName      = "/generated.AuthenticationService/SaveToken"
+	AuthenticationService_GetAuthStatus_FullMethodName  = "/generated.AuthenticationService/GetAuthStatus"
+	AuthenticationService_GetOAuthClient_FullMethodName = "/generated.AuthenticationService/GetOAuthClient"
+	AuthenticationService_Logout_FullMethodName         = "/generated.AuthenticationService/Logout"
 )
 
 // AuthenticationServiceClient is the client API for AuthenticationService service.
@@ -168,7 +364,9 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type AuthenticationServiceClient interface {
 	SaveToken(ctx context.Context, in *OAuth2Token, opts ...grpc.CallOption) (*Empty, error)
-	GetToken(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*OAuth2Token, error)
+	GetAuthStatus(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*AuthStatus, error)
+	GetOAuthClient(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*OAuthClient, error)
+	Logout(ctx context.Context, in *LogoutRequest, opts ...grpc.CallOption) (*Empty, error)
 }
 
 type authenticationServiceClient struct {
@@ -189,10 +387,30 @@ func (c *authenticationServiceClient) SaveToken(ctx context.Context, in *OAuth2T
 	return out, nil
 }
 
-func (c *authenticationServiceClient) GetToken(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*OAuth2Token, error) {
+func (c *authenticationServiceClient) GetAuthStatus(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*AuthStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuthStatus)
+	err := c.cc.Invoke(ctx, AuthenticationService_GetAuthStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authenticationServiceClient) GetOAuthClient(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*OAuthClient, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(OAuth2Token)
-	err := c.cc.Invoke(ctx, AuthenticationService_GetToken_FullMethodName, in, out, cOpts...)
+	out := new(OAuthClient)
+	err := c.cc.Invoke(ctx, AuthenticationService_GetOAuthClient_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authenticationServiceClient) Logout(ctx context.Context, in *LogoutRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, AuthenticationService_Logout_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -204,7 +422,9 @@ func (c *authenticationServiceClient) GetToken(ctx context.Context, in *Empty, o
 // for forward compatibility.
 type AuthenticationServiceServer interface {
 	SaveToken(context.Context, *OAuth2Token) (*Empty, error)
-	GetToken(context.Context, *Empty) (*OAuth2Token, error)
+	GetAuthStatus(context.Context, *Empty) (*AuthStatus, error)
+	GetOAuthClient(context.Context, *Empty) (*OAuthClient, error)
+	Logout(context.Context, *LogoutRequest) (*Empty, error)
 	mustEmbedUnimplementedAuthenticationServiceServer()
 }
 
@@ -218,8 +438,14 @@ type UnimplementedAuthenticationServiceServer struct{}
 func (UnimplementedAuthenticationServiceServer) SaveToken(context.Context, *OAuth2Token) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SaveToken not implemented")
 }
-func (UnimplementedAuthenticationServiceServer) GetToken(context.Context, *Empty) (*OAuth2Token, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetToken not implemented")
+func (UnimplementedAuthenticationServiceServer) GetAuthStatus(context.Context, *Empty) (*AuthStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuthStatus not implemented")
+}
+func (UnimplementedAuthenticationServiceServer) GetOAuthClient(context.Context, *Empty) (*OAuthClient, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOAuthClient not implemented")
+}
+func (UnimplementedAuthenticationServiceServer) Logout(context.Context, *LogoutRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Logout not implemented")
 }
 func (UnimplementedAuthenticationServiceServer) mustEmbedUnimplementedAuthenticationServiceServer() {}
 func (UnimplementedAuthenticationServiceServer) testEmbeddedByValue()                               {}
@@ -260,20 +486,56 @@ func _AuthenticationService_SaveToken_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
-func _AuthenticationService_GetToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+func _AuthenticationService_GetAuthStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(AuthenticationServiceServer).GetToken(ctx, in)
+		return srv.(AuthenticationServiceServer).GetAuthStatus(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: AuthenticationService_GetToken_FullMethodName,
+		FullMethod: AuthenticationService_GetAuthStatus_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AuthenticationServiceServer).GetToken(ctx, req.(*Empty))
+		return srv.(AuthenticationServiceServer).GetAuthStatus(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthenticationService_GetOAuthClient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthenticationServiceServer).GetOAuthClient(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthenticationService_GetOAuthClient_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthenticationServiceServer).GetOAuthClient(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthenticationService_Logout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogoutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthenticationServiceServer).Logout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthenticationService_Logout_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthenticationServiceServer).Logout(ctx, req.(*LogoutRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -290,8 +552,16 @@ var AuthenticationService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _AuthenticationService_SaveToken_Handler,
 		},
 		{
-			MethodName: "GetToken",
-			Handler:    _AuthenticationService_GetToken_Handler,
+			MethodName: "GetAuthStatus",
+			Handler:    _AuthenticationService_GetAuthStatus_Handler,
+		},
+		{
+			MethodName: "GetOAuthClient",
+			Handler:    _AuthenticationService_GetOAuthClient_Handler,
+		},
+		{
+			MethodName: "Logout",
+			Handler:    _AuthenticationService_Logout_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},